@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+// adminApi serves actions changing guard state, every request must have 'Authorization: Bearer ADMIN_TOKEN'
+type adminApi struct {
+	config      guard.Config
+	operator    string // transactions are accepted only for this validator operator
+	gsm         *guard.GuardStateMachine
+	broadcaster *guard.Broadcaster
+	setTxData   func(txData []byte)
+	logger      tmlog.Logger
+}
+
+type setOnlineRequest struct {
+	Tx        string `json:"tx"`         // signed set_online transaction in hex
+	OfflineTx string `json:"offline_tx"` // optional: next set_offline transaction to re-arm protection
+	Confirm   bool   `json:"confirm"`    // without confirmation only checks are performed
+}
+
+type setOnlineResponse struct {
+	Confirmed  bool                   `json:"confirmed"`
+	NodeHeight int64                  `json:"node_height,omitempty"`
+	Validator  string                 `json:"validator,omitempty"`
+	Sequence   uint64                 `json:"sequence,omitempty"`
+	Broadcast  *guard.BroadcastReport `json:"broadcast,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+type txUpdateRequest struct {
+	Tx string `json:"tx"` // signed set_offline transaction in hex
+}
+
+type txUpdateResponse struct {
+	Validator string `json:"validator,omitempty"`
+	Sequence  uint64 `json:"sequence,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (a *adminApi) register(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/set-online", a.handleSetOnline)
+	mux.HandleFunc("/admin/tx", a.handleTxUpdate)
+}
+
+func (a *adminApi) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.config.AdminToken)) == 1
+}
+
+func (a *adminApi) handleSetOnline(w http.ResponseWriter, r *http.Request) {
+	var resp setOnlineResponse
+	if r.Method != http.MethodPost {
+		writeJson(w, http.StatusMethodNotAllowed, setOnlineResponse{Error: "POST is required"})
+		return
+	}
+	if !a.authorized(r) {
+		writeJson(w, http.StatusUnauthorized, setOnlineResponse{Error: "invalid admin token"})
+		return
+	}
+	var req setOnlineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJson(w, http.StatusBadRequest, setOnlineResponse{Error: fmt.Sprintf("decode request: %s", err.Error())})
+		return
+	}
+	tx, info, err := a.decodeTxHex(req.Tx, txdata.TypeMsgSetOnline)
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, setOnlineResponse{Error: err.Error()})
+		return
+	}
+	resp.Validator, resp.Sequence = info.Validator, info.Sequence
+	var offlineTx []byte
+	if req.OfflineTx > "" {
+		offlineTx, _, err = a.decodeTxHex(req.OfflineTx, txdata.TypeMsgSetOffline)
+		if err != nil {
+			resp.Error = err.Error()
+			writeJson(w, http.StatusBadRequest, resp)
+			return
+		}
+	}
+	if a.config.ValidatorNode == "" {
+		resp.Error = "VALIDATOR_NODE is not configured, can't check validator node"
+		writeJson(w, http.StatusConflict, resp)
+		return
+	}
+	status, err := guard.CheckValidatorNode(a.config.ValidatorNode, a.config)
+	resp.NodeHeight = status.LatestBlockHeight
+	if err != nil {
+		resp.Error = err.Error()
+		writeJson(w, http.StatusConflict, resp)
+		return
+	}
+	if !req.Confirm {
+		writeJson(w, http.StatusOK, resp)
+		return
+	}
+
+	resp.Confirmed = true
+	a.logger.Info("admin: broadcast set_online", "validator", info.Validator)
+	report := a.broadcaster.Broadcast(tx)
+	resp.Broadcast = &report
+	a.gsm.AuditBroadcast("set_online", &report, "")
+	if !report.Included {
+		resp.Error = "set_online transaction is not included in block"
+		writeJson(w, http.StatusBadGateway, resp)
+		return
+	}
+	if offlineTx != nil {
+		a.setTxData(offlineTx)
+	}
+	a.gsm.StartGracePeriod()
+	writeJson(w, http.StatusOK, resp)
+}
+
+// handleTxUpdate replaces set_offline transaction, watchers check it on next blocks
+func (a *adminApi) handleTxUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJson(w, http.StatusMethodNotAllowed, txUpdateResponse{Error: "POST is required"})
+		return
+	}
+	if !a.authorized(r) {
+		writeJson(w, http.StatusUnauthorized, txUpdateResponse{Error: "invalid admin token"})
+		return
+	}
+	var req txUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJson(w, http.StatusBadRequest, txUpdateResponse{Error: fmt.Sprintf("decode request: %s", err.Error())})
+		return
+	}
+	tx, info, err := a.decodeTxHex(req.Tx, txdata.TypeMsgSetOffline)
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, txUpdateResponse{Error: err.Error()})
+		return
+	}
+	a.logger.Info("admin: new set_offline transaction", "validator", info.Validator, "sequence", info.Sequence)
+	a.setTxData(tx)
+	writeJson(w, http.StatusOK, txUpdateResponse{Validator: info.Validator, Sequence: info.Sequence})
+}
+
+// guardedOperator returns VALIDATOR_OPERATOR or validator of SET_OFFLINE_TX: VALIDATOR_ADDRESS is consensus
+// address and it can't be compared with validator of transaction
+func guardedOperator(config guard.Config) (string, error) {
+	if config.ValidatorOperator > "" {
+		return config.ValidatorOperator, nil
+	}
+	if config.SetOfflineTx == "" {
+		return "", fmt.Errorf("validator operator is unknown: set VALIDATOR_OPERATOR or SET_OFFLINE_TX")
+	}
+	_, info, err := decodeTxHex(config.SetOfflineTx, txdata.TypeMsgSetOffline)
+	if err != nil {
+		return "", fmt.Errorf("validator operator is unknown: SET_OFFLINE_TX: %s", err.Error())
+	}
+	return info.Validator, nil
+}
+
+// decodeTxHex decodes transaction and checks that it is for guarded validator
+func (a *adminApi) decodeTxHex(txHex string, msgType string) ([]byte, txdata.TxInfo, error) {
+	tx, info, err := decodeTxHex(txHex, msgType)
+	if err != nil {
+		return nil, txdata.TxInfo{}, err
+	}
+	if info.Validator != a.operator {
+		return nil, txdata.TxInfo{}, fmt.Errorf("transaction is for validator %s, guard protects %s", info.Validator, a.operator)
+	}
+	return tx, info, nil
+}
+
+// decodeTxHex decodes signed transaction and checks that it contains single message of expected type
+func decodeTxHex(txHex string, msgType string) ([]byte, txdata.TxInfo, error) {
+	tx, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, txdata.TxInfo{}, fmt.Errorf("can't decode tx data: %s", err.Error())
+	}
+	info, err := txdata.Decode(tx)
+	if err != nil {
+		return nil, txdata.TxInfo{}, err
+	}
+	if len(info.Messages) != 1 || info.Messages[0] != msgType {
+		return nil, txdata.TxInfo{}, fmt.Errorf("expected transaction with single %s, got %v", msgType, info.Messages)
+	}
+	return tx, info, nil
+}
+
+func writeJson(w http.ResponseWriter, code int, v interface{}) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		bz = []byte("{}")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(bz)
+}
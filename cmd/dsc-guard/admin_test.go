@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/keys"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+const testMnemonic = "bulb raw claw magnet romance jaguar life cluster solve random laptop salmon pottery subject country aware actual hope wedding hawk amused cage secret network"
+
+// otherMnemonic is key of validator which is not protected by guard
+const otherMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// dummyWriter implement io.Writer
+type dummyWriter struct{}
+
+func (d dummyWriter) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+// testTx returns signed set_offline (or set_online) transaction in hex
+func testTx(t *testing.T, mnemonic string, online bool, sequence uint64) string {
+	signer, err := keys.FromMnemonic(mnemonic)
+	require.NoError(t, err)
+	data := txdata.SignerData{ChainID: "decimal_2020-22100701", AccountNumber: 42, Sequence: sequence}
+	build := txdata.BuildSetOffline
+	if online {
+		build = txdata.BuildSetOnline
+	}
+	bz, err := build(signer, data, sdk.NewCoin("del", sdk.ZeroInt()))
+	require.NoError(t, err)
+	return hex.EncodeToString(bz)
+}
+
+func postJson(t *testing.T, url, token string, body interface{}) (int, map[string]interface{}) {
+	bz, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bz))
+	require.NoError(t, err)
+	if token > "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return resp.StatusCode, result
+}
+
+// adminServer serves admin API with token 'secret' for validator of testMnemonic,
+// replaced transaction is stored to txData
+func adminServer(t *testing.T, txData *[]byte) *httptest.Server {
+	logger := tmlog.NewTMLogger(dummyWriter{})
+	config := guard.Config{AdminToken: "secret", SetOfflineTx: testTx(t, testMnemonic, false, 1)}
+	operator, err := guardedOperator(config)
+	require.NoError(t, err)
+	admin := &adminApi{
+		config:    config,
+		operator:  operator,
+		gsm:       guard.NewGuardState(logger, config, nil),
+		setTxData: func(bz []byte) { *txData = bz },
+		logger:    logger,
+	}
+	mux := http.NewServeMux()
+	admin.register(mux)
+	return httptest.NewServer(mux)
+}
+
+func TestAdminApi(t *testing.T) {
+	var txData []byte
+	srv := adminServer(t, &txData)
+	defer srv.Close()
+	offlineTx := testTx(t, testMnemonic, false, 7)
+
+	// token is checked before request
+	for _, path := range []string{"/admin/tx", "/admin/set-online"} {
+		code, resp := postJson(t, srv.URL+path, "", txUpdateRequest{Tx: offlineTx})
+		require.Equal(t, http.StatusUnauthorized, code)
+		require.Equal(t, "invalid admin token", resp["error"])
+		code, _ = postJson(t, srv.URL+path, "wrong", txUpdateRequest{Tx: offlineTx})
+		require.Equal(t, http.StatusUnauthorized, code)
+	}
+	resp, err := http.Get(srv.URL + "/admin/tx")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	require.Nil(t, txData)
+
+	// only set_offline transaction replaces current one
+	code, result := postJson(t, srv.URL+"/admin/tx", "secret", txUpdateRequest{Tx: testTx(t, testMnemonic, true, 7)})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, result["error"], "expected transaction with single")
+	require.Nil(t, txData)
+
+	code, result = postJson(t, srv.URL+"/admin/tx", "secret", txUpdateRequest{Tx: offlineTx})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, float64(7), result["sequence"])
+	require.NotEmpty(t, result["validator"])
+	require.Equal(t, offlineTx, hex.EncodeToString(txData))
+
+	// transactions of other validator are rejected
+	code, result = postJson(t, srv.URL+"/admin/tx", "secret", txUpdateRequest{Tx: testTx(t, otherMnemonic, false, 7)})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, result["error"], "guard protects")
+	require.Equal(t, offlineTx, hex.EncodeToString(txData))
+	code, result = postJson(t, srv.URL+"/admin/set-online", "secret", setOnlineRequest{Tx: testTx(t, otherMnemonic, true, 8)})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, result["error"], "guard protects")
+	code, result = postJson(t, srv.URL+"/admin/set-online", "secret", setOnlineRequest{
+		Tx:        testTx(t, testMnemonic, true, 8),
+		OfflineTx: testTx(t, otherMnemonic, false, 9),
+	})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, result["error"], "guard protects")
+
+	// set_online requires validator node
+	code, result = postJson(t, srv.URL+"/admin/set-online", "secret", setOnlineRequest{Tx: testTx(t, testMnemonic, true, 8)})
+	require.Equal(t, http.StatusConflict, code)
+	require.Contains(t, result["error"], "VALIDATOR_NODE")
+}
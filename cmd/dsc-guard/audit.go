@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit [file...]",
+		Short: "Print audit log (AUDIT_LOG or given files) in human readable form",
+	}
+	recordType := cmd.Flags().String("type", "", "only records of type: transition or broadcast")
+	sinceStr := cmd.Flags().String("since", "", "only records not older than RFC3339 time")
+	cmd.RunE = func(cmd *cobra.Command, files []string) error {
+		if len(files) == 0 {
+			config, _, err := loadConfig(cmd, true)
+			if err != nil {
+				return err
+			}
+			if config.AuditLog == "" {
+				return fmt.Errorf("AUDIT_LOG is not configured")
+			}
+			files = []string{config.AuditLog}
+		}
+		var since time.Time
+		if *sinceStr > "" {
+			var err error
+			since, err = time.Parse(time.RFC3339, *sinceStr)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %s", err.Error())
+			}
+		}
+		for _, path := range files {
+			err := printAudit(os.Stdout, path, *recordType, since)
+			if err != nil {
+				return fmt.Errorf("%s: %s", path, err.Error())
+			}
+		}
+		return nil
+	}
+	return cmd
+}
+
+func printAudit(w io.Writer, path string, recordType string, since time.Time) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return guard.ReadAudit(file, func(record guard.AuditRecord) error {
+		if (recordType > "" && record.Type != recordType) || record.Time.Before(since) {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, guard.FormatAudit(record))
+		return err
+	})
+}
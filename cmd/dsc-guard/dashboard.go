@@ -0,0 +1,96 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// DashboardEvents is count of recent events on dashboard, sign events are shown as window instead
+const DashboardEvents = 30
+
+// DashboardRefresh is interval of page refresh in seconds
+const DashboardRefresh = 10
+
+//go:embed dashboard.html
+var dashboardHtml string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"stateClass": func(state string) string {
+		switch state {
+		case "watching":
+			return "ok"
+		case "starting", "validator_offline":
+			return "warn"
+		}
+		return "bad"
+	},
+	"txClass": func(status string) string {
+		switch status {
+		case "valid":
+			return "ok"
+		case "unknown":
+			return "warn"
+		}
+		return "bad"
+	},
+	"watcherClass": func(state string) string {
+		if state == "watching" {
+			return "ok"
+		}
+		return "warn"
+	},
+	"details": func(details map[string]interface{}) string {
+		var keys []string
+		for k := range details {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, details[k]))
+		}
+		return strings.Join(parts, " ")
+	},
+}).Parse(dashboardHtml))
+
+type dashboardData struct {
+	Validator string
+	Updated   time.Time
+	Refresh   int
+	Status    guard.GuardStatus
+	Watchers  []guard.WatcherHealth
+	Events    []guard.HistoryEvent // newest first
+}
+
+// serveDashboard renders self-contained HTML page with status of guard
+func serveDashboard(gsm *guard.GuardStateMachine, config guard.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := dashboardData{
+			Validator: config.ValidatorAddress,
+			Updated:   time.Now(),
+			Refresh:   DashboardRefresh,
+			Status:    gsm.Status(),
+			Watchers:  gsm.Watchers(),
+		}
+		events := gsm.Events(guard.HistoryFilter{})
+		for i := len(events) - 1; i >= 0 && len(data.Events) < DashboardEvents; i-- {
+			if events[i].Type == guard.HistorySign {
+				continue
+			}
+			data.Events = append(data.Events, events[i])
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := dashboardTemplate.Execute(w, data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
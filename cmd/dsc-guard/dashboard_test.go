@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+func TestDashboard(t *testing.T) {
+	config := guard.Config{MissedBlocksLimit: 3, MissedBlocksWindow: 5, ValidatorAddress: "AA"}
+	gsm := guard.NewGuardState(tmlog.NewTMLogger(dummyWriter{}), config, nil)
+	go gsm.Start()
+	defer gsm.Stop()
+	require.Eventually(t, func() bool { return gsm.Liveness(time.Now()) == nil }, 5*time.Second, 10*time.Millisecond)
+	gsm.ReportWatcher("http://a:26657", guard.WatcherWatching)
+	gsm.ReportWatcher("http://b:26657", guard.WatcherConnecting)
+	gsm.ReportWatcherError("http://b:26657", fmt.Errorf("<b>connection refused</b>"))
+	gsm.ReportValidatorOnline("http://a:26657", 1, true)
+	require.Eventually(t, func() bool { return gsm.Status().ValidatorOnline }, 5*time.Second, 10*time.Millisecond)
+	gsm.SetSign(2, true)
+	gsm.SetSign(3, false)
+
+	srv := httptest.NewServer(serveDashboard(gsm, config))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	bz, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	page := string(bz)
+
+	require.Contains(t, page, "validator AA")
+	require.Contains(t, page, `<span class="ok">online</span>`)
+	require.Contains(t, page, "<td>http://b:26657</td>")
+	// error of watcher is escaped
+	require.Contains(t, page, "&lt;b&gt;connection refused&lt;/b&gt;")
+	require.NotContains(t, page, "<b>connection")
+	// window has only blocks from height 1
+	require.Equal(t, 3, strings.Count(page, `<div class="block `))
+	require.Contains(t, page, `title="3: missed"`)
+	require.Contains(t, page, "1 of 5, set_offline at 3")
+	// sign events are shown as window only
+	require.Contains(t, page, "<td>"+guard.HistoryWatcherError+"</td>")
+	require.NotContains(t, page, "<td>"+guard.HistorySign+"</td>")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// writeGuardConfig replaces (or appends) SET_OFFLINE_TX in guard .env file, other lines are kept as is
+func writeGuardConfig(path string, txHex string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(string(bz), "\n"), "\n")
+	found := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "SET_OFFLINE_TX=") {
+			lines[i] = fmt.Sprintf("SET_OFFLINE_TX=\"%s\"", txHex)
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, fmt.Sprintf("SET_OFFLINE_TX=\"%s\"", txHex))
+	}
+	// write to temporary file and rename to not leave broken config
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), stat.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// pushToGuard sends transaction to admin tx-update endpoint of running guard
+func pushToGuard(guardUrl, token, txHex string) error {
+	bz, err := json.Marshal(map[string]string{"tx": txHex})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(guardUrl, "/")+"/admin/tx", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http code %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGuardConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("# guard\nNODES_ENDPOINTS=http://localhost:26657\n  SET_OFFLINE_TX=\"aa\"\nMISSED_BLOCKS_LIMIT=8\n"), 0600))
+	require.NoError(t, writeGuardConfig(path, "bb"))
+	bz, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# guard\nNODES_ENDPOINTS=http://localhost:26657\nSET_OFFLINE_TX=\"bb\"\nMISSED_BLOCKS_LIMIT=8\n", string(bz))
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	require.NoFileExists(t, path+".tmp")
+
+	// absent setting is appended
+	require.NoError(t, os.WriteFile(path, []byte("NODES_ENDPOINTS=http://localhost:26657"), 0600))
+	require.NoError(t, writeGuardConfig(path, "cc"))
+	bz, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "NODES_ENDPOINTS=http://localhost:26657\nSET_OFFLINE_TX=\"cc\"\n", string(bz))
+
+	// config is not created
+	require.Error(t, writeGuardConfig(filepath.Join(t.TempDir(), "absent.env"), "dd"))
+}
+
+func TestPushToGuard(t *testing.T) {
+	var txData []byte
+	srv := adminServer(t, &txData)
+	defer srv.Close()
+
+	offlineTx := testTx(t, testMnemonic, false, 3)
+	err := pushToGuard(srv.URL+"/", "wrong", offlineTx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "http code 401")
+	require.Nil(t, txData)
+
+	require.NoError(t, pushToGuard(srv.URL+"/", "secret", offlineTx))
+	require.Equal(t, offlineTx, hex.EncodeToString(txData))
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+	"bitbucket.org/decimalteam/dsc-guard/keys"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+func newGentxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gentx",
+		Short: "Sign set_offline transactions for SET_OFFLINE_TX",
+		Args:  cobra.NoArgs,
+	}
+	offline := cmd.Flags().Bool("offline", false, "don't connect to node, take chain id, account number and sequence from flags")
+	node := cmd.Flags().String("node", "", "Tendermint RPC endpoint (default NODE_ENDPOINT or first of NODES_ENDPOINTS)")
+	chainID := cmd.Flags().String("chain-id", "", "chain id (offline mode)")
+	accountNumber := cmd.Flags().Uint64("account-number", 0, "account number (offline mode)")
+	sequence := cmd.Flags().Uint64("sequence", 0, "starting account sequence (offline mode)")
+	feeStr := cmd.Flags().String("fee", "0del", "transaction fee")
+	count := cmd.Flags().Uint64("count", 1, "count of transactions with sequential sequences")
+	guardConfig := cmd.Flags().String("guard-config", "", "guard .env file to write SET_OFFLINE_TX")
+	guardUrl := cmd.Flags().String("guard", "", "running guard admin API url to push transaction, for example http://localhost:11111")
+	var keyOpts keys.Options
+	keyFlags := flag.NewFlagSet("keys", flag.ContinueOnError)
+	keys.BindFlags(keyFlags, &keyOpts)
+	cmd.Flags().AddGoFlagSet(keyFlags)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if *count < 1 {
+			return fmt.Errorf("--count must be at least 1")
+		}
+		// configuration file is optional
+		config, v, err := loadConfig(cmd, false)
+		if err != nil {
+			return err
+		}
+		keyOpts.EnvMnemonic = v.GetString("MNEMONIC")
+		adminToken := config.AdminToken
+		if *node == "" {
+			*node = v.GetString("NODE_ENDPOINT")
+		}
+		if *node == "" && config.NodesEndpoints > "" {
+			*node = strings.TrimSpace(strings.Split(config.NodesEndpoints, ",")[0])
+		}
+		// missing settings are taken from guard configuration
+		if *guardConfig != "" {
+			guardViper := viper.New()
+			guardViper.SetConfigFile(*guardConfig)
+			if err := guardViper.ReadInConfig(); err != nil {
+				return fmt.Errorf("can't read guard config: %s", err.Error())
+			}
+			if *node == "" {
+				*node = strings.TrimSpace(strings.Split(guardViper.GetString("NODES_ENDPOINTS"), ",")[0])
+			}
+			if adminToken == "" {
+				adminToken = guardViper.GetString("ADMIN_TOKEN")
+			}
+		}
+
+		fee, err := sdk.ParseCoinNormalized(*feeStr)
+		if err != nil {
+			return fmt.Errorf("can't parse fee: %s", err.Error())
+		}
+		signer, err := keys.Load(keyOpts)
+		if err != nil {
+			return fmt.Errorf("can't load signing key: %s", err.Error())
+		}
+		address, err := txdata.SignerAddress(signer)
+		if err != nil {
+			return fmt.Errorf("can't get account address: %s", err.Error())
+		}
+
+		var data txdata.SignerData
+		if *offline {
+			if *chainID == "" {
+				return fmt.Errorf("offline mode requires --chain-id")
+			}
+			data = txdata.SignerData{ChainID: *chainID, AccountNumber: *accountNumber, Sequence: *sequence}
+		} else {
+			if *node == "" {
+				return fmt.Errorf("node endpoint is not set: use --node, NODE_ENDPOINT, --nodes or --guard-config")
+			}
+			data, err = querySignerData(*node, address)
+			if err != nil {
+				return fmt.Errorf("can't get account data from %s: %s", *node, err.Error())
+			}
+		}
+
+		// transactions for sequences [sequence, sequence+count), one per line:
+		// if some sequence is used by other transaction, next line can be used
+		fmt.Fprintf(os.Stderr, "chain id %s, account %s, account number %d, sequences %d..%d\n",
+			data.ChainID, address, data.AccountNumber, data.Sequence, data.Sequence+*count-1)
+		var txs []string
+		for i := uint64(0); i < *count; i++ {
+			bz, err := txdata.BuildSetOffline(signer, data, fee)
+			if err != nil {
+				return fmt.Errorf("can't build set_offline transaction: %s", err.Error())
+			}
+			txs = append(txs, hex.EncodeToString(bz))
+			fmt.Println(txs[i])
+			data.Sequence++
+		}
+
+		// first transaction is delivered, others are reserve
+		if *guardConfig != "" {
+			if err := writeGuardConfig(*guardConfig, txs[0]); err != nil {
+				return fmt.Errorf("can't write guard config: %s", err.Error())
+			}
+			fmt.Fprintf(os.Stderr, "SET_OFFLINE_TX is written to %s\n", *guardConfig)
+		}
+		if *guardUrl != "" {
+			if err := pushToGuard(*guardUrl, adminToken, txs[0]); err != nil {
+				return fmt.Errorf("can't push transaction to guard: %s", err.Error())
+			}
+			fmt.Fprintf(os.Stderr, "transaction is pushed to guard %s\n", *guardUrl)
+		}
+		return nil
+	}
+	return cmd
+}
+
+// querySignerData gets chain id from node status and account data from auth module
+func querySignerData(node string, address string) (txdata.SignerData, error) {
+	client := fastclient.NewFastClient(node, 10*time.Second)
+	status, err := client.Status()
+	if err != nil {
+		return txdata.SignerData{}, err
+	}
+	account, err := txdata.QueryAccount(client, address)
+	if err != nil {
+		return txdata.SignerData{}, err
+	}
+	return txdata.SignerData{ChainID: status.Network, AccountNumber: account.AccountNumber, Sequence: account.Sequence}, nil
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGentxCount(t *testing.T) {
+	root := newRootCmd()
+	root.SetArgs([]string{"gentx", "--offline", "--chain-id", "test", "--count", "0", "--key-source", "env"})
+	err := root.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--count must be at least 1")
+}
@@ -1,116 +1,104 @@
 package main
 
 import (
-	"context"
-	"encoding/hex"
 	"fmt"
-	"net/http"
 	"os"
-	"os/signal"
-	"strings"
-	"sync"
-	"syscall"
-	"time"
+	"runtime"
 
-	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	tmlog "github.com/tendermint/tendermint/libs/log"
-)
-
-func main() {
-	var watchers []*guard.Watcher
-	var wg sync.WaitGroup
-	var exclusiveCheck = guard.NewCooldownLock(time.Second * 6) // up to 6 seconds - time of block
-	var httpServer *http.Server
 
-	logger := tmlog.NewTMLogger(os.Stdout)
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/notify"
+)
 
-	viper.SetConfigFile(".env")
-	err := viper.ReadInConfig()
-	if err != nil {
-		logger.Error(fmt.Sprintf("viper.ReadInConfig error: %s", err.Error()))
-		os.Exit(1)
-	}
+// version is set at build: go build -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// configFlags override values of configuration file
+var configFlags = []struct {
+	name  string
+	key   string
+	isInt bool
+	usage string
+}{
+	{"nodes", "NODES_ENDPOINTS", false, "RPC endpoints of nodes separated by ','"},
+	{"validator", "VALIDATOR_ADDRESS", false, "validator address in hex"},
+	{"set-offline-tx", "SET_OFFLINE_TX", false, "signed set_offline transaction in hex"},
+	{"http-listener", "HTTP_LISTENER", false, "address of http status page and API"},
+	{"missed-blocks-limit", "MISSED_BLOCKS_LIMIT", true, "missed blocks in window to send set_offline"},
+	{"missed-blocks-window", "MISSED_BLOCKS_WINDOW", true, "size of sign window in blocks"},
+	{"admin-token", "ADMIN_TOKEN", false, "token of admin API"},
+	{"audit-log", "AUDIT_LOG", false, "path of audit log"},
+	{"log-level", "LOG_LEVEL", false, "debug, info or error"},
+	{"log-format", "LOG_FORMAT", false, "plain, logfmt or json"},
+}
 
-	config := guard.Config{}
-	err = viper.Unmarshal(&config)
-	if err != nil {
-		logger.Error(fmt.Sprintf("viper.Unmarshal error: %s", err.Error()))
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
 		os.Exit(1)
 	}
+}
 
-	txData, err := hex.DecodeString(config.SetOfflineTx)
-	if err != nil {
-		logger.Error(fmt.Sprintf("can't decode tx data: %s", err.Error()))
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "dsc-guard",
+		Short:        "Decimal Smart Chain validator guard",
+		SilenceUsage: true,
 	}
-
-	logger.Info("Start DSC guard")
-
-	gsm := guard.NewGuardState(logger, config, func() {
-		for _, w := range watchers {
-			w.SendOffline()
+	root.PersistentFlags().String("config", ".env", "path to configuration file")
+	for _, f := range configFlags {
+		usage := fmt.Sprintf("%s (overrides %s)", f.usage, f.key)
+		if f.isInt {
+			root.PersistentFlags().Int(f.name, 0, usage)
+		} else {
+			root.PersistentFlags().String(f.name, "", usage)
 		}
-	})
-
-	wg.Add(1)
-	go func() {
-		gsm.Start()
-		wg.Done()
-	}()
-
-	nodes := strings.Split(config.NodesEndpoints, ",")
-	for _, node := range nodes {
-		w := guard.NewWatcher(
-			node,
-			config,
-			gsm,
-			logger,
-			exclusiveCheck,
-		)
-		w.SetTxData(txData)
-		wg.Add(1)
-		go func() {
-			w.Start()
-			wg.Done()
-		}()
-		watchers = append(watchers, w)
 	}
+	root.AddCommand(
+		newRunCmd(),
+		newStatusCmd(),
+		newWindowCmd(),
+		newCheckTxCmd(),
+		newDecodeTxCmd(),
+		newGentxCmd(),
+		newSetOnlineCmd(),
+		newAuditCmd(),
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print version",
+			Args:  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				fmt.Printf("dsc-guard %s (%s %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+			},
+		},
+	)
+	return root
+}
 
-	if config.HttpListener > "" {
-		httpServer = &http.Server{
-			Addr:        config.HttpListener,
-			Handler:     nil, // default http mux
-			ReadTimeout: 5 * time.Second,
-		}
-		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-			w.Write(gsm.GetJsonStatus())
-		})
-		wg.Add(1)
-		go func() {
-			err := httpServer.ListenAndServe()
-			if err != nil && err != http.ErrServerClosed {
-				logger.Error(fmt.Sprintf("error in http.ListenAndServe: %s", err.Error()))
-			}
-			wg.Done()
-		}()
+// loadConfig reads configuration file (--config, .env format) and applies flags, file is optional if required is false
+func loadConfig(cmd *cobra.Command, required bool) (guard.Config, *viper.Viper, error) {
+	config := guard.Config{}
+	v := viper.New()
+	path, _ := cmd.Flags().GetString("config")
+	v.SetConfigFile(path)
+	v.SetConfigType("env")
+	// zero (false) is valid value of these settings, so default is applied only if setting is absent
+	v.SetDefault("WEBHOOK_RETRIES", notify.DefaultWebhookRetries)
+	v.SetDefault("ENABLE_GRACE_PERIOD", true)
+	v.SetDefault("GRACE_PERIOD_DURATION", guard.DefaultGracePeriodDuration)
+	err := v.ReadInConfig()
+	if err != nil && (required || !os.IsNotExist(err)) {
+		return config, v, fmt.Errorf("read config %s: %s", path, err.Error())
 	}
-
-	// TODO: add http endpoint for transaction dynamic update
-
-	exit := make(chan os.Signal, 1)
-	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
-
-	// wait for stop/restart/etc
-	<-exit
-
-	// graceful shotdown
-	for _, w := range watchers {
-		w.Stop()
+	for _, f := range configFlags {
+		if flag := cmd.Flags().Lookup(f.name); flag != nil && flag.Changed {
+			v.Set(f.key, flag.Value.String())
+		}
 	}
-	gsm.Stop()
-	if httpServer != nil {
-		httpServer.Shutdown(context.Background())
+	err = v.Unmarshal(&config)
+	if err != nil {
+		return config, v, fmt.Errorf("parse config %s: %s", path, err.Error())
 	}
-
-	wg.Wait()
+	return config, v, nil
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/notify"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("NODES_ENDPOINTS=http://a:26657\nMISSED_BLOCKS_LIMIT=8\nMISSED_BLOCKS_WINDOW=24\n"), 0600))
+
+	root := newRootCmd()
+	require.NoError(t, root.ParseFlags([]string{"--config", path, "--nodes", "http://b:26657", "--missed-blocks-limit", "5"}))
+	config, _, err := loadConfig(root, true)
+	require.NoError(t, err)
+	require.Equal(t, "http://b:26657", config.NodesEndpoints)
+	require.Equal(t, 5, config.MissedBlocksLimit)
+	require.Equal(t, 24, config.MissedBlocksWindow)
+	require.Equal(t, notify.DefaultWebhookRetries, config.WebhookRetries)
+	require.True(t, config.EnableGracePeriod)
+	require.Equal(t, guard.DefaultGracePeriodDuration, config.GracePeriodDuration)
+
+	// zero retries is kept
+	require.NoError(t, os.WriteFile(path, []byte("WEBHOOK_RETRIES=0\nENABLE_GRACE_PERIOD=false\n"), 0600))
+	config, _, err = loadConfig(root, true)
+	require.NoError(t, err)
+	require.Equal(t, 0, config.WebhookRetries)
+	require.False(t, config.EnableGracePeriod)
+	require.Equal(t, "http://b:26657", config.NodesEndpoints)
+
+	// file is optional only if it is not required
+	root = newRootCmd()
+	require.NoError(t, root.ParseFlags([]string{"--config", filepath.Join(t.TempDir(), "absent.env"), "--validator", "AA"}))
+	config, _, err = loadConfig(root, false)
+	require.NoError(t, err)
+	require.Equal(t, "AA", config.ValidatorAddress)
+	_, _, err = loadConfig(root, true)
+	require.Error(t, err)
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/notify"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+)
+
+func newRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Run guard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, _, err := loadConfig(cmd, true)
+			if err != nil {
+				return err
+			}
+			return runGuard(config)
+		},
+	}
+}
+
+// runGuard starts watchers and http server, it returns after SIGINT/SIGTERM
+func runGuard(config guard.Config) error {
+	var watchers []*guard.Watcher
+	var wg sync.WaitGroup
+	var exclusiveCheck = guard.NewCooldownLock(time.Second * 6) // up to 6 seconds - time of block
+	var httpServer *http.Server
+	var txMu sync.Mutex
+
+	logger, err := guard.NewLogger(config, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("LOG_LEVEL/LOG_FORMAT: %s", err.Error())
+	}
+
+	_, err = guard.ParseWarnings(config.MissedBlocksWarnings)
+	if err != nil {
+		return fmt.Errorf("MISSED_BLOCKS_WARNINGS: %s", err.Error())
+	}
+
+	if config.DigestPeriod > "" {
+		_, err = guard.NextDigest(time.Now(), config.DigestPeriod)
+		if err != nil {
+			return fmt.Errorf("DIGEST_PERIOD: %s", err.Error())
+		}
+	}
+
+	// admin API accepts transactions only for guarded validator
+	var operator string
+	if config.AdminToken > "" {
+		operator, err = guardedOperator(config)
+		if err != nil {
+			return fmt.Errorf("ADMIN_TOKEN: %s", err.Error())
+		}
+	}
+
+	txData, err := hex.DecodeString(config.SetOfflineTx)
+	if err != nil {
+		logger.Error("can't decode tx data", "err", err.Error())
+	}
+
+	logger.Info("Start DSC guard")
+
+	// set_offline transaction can be replaced in runtime (after set_online)
+	setTxData := func(bz []byte) {
+		txMu.Lock()
+		defer txMu.Unlock()
+		txData = bz
+		for _, w := range watchers {
+			w.SetTxData(bz)
+		}
+	}
+
+	var gsm *guard.GuardStateMachine
+	broadcaster := guard.NewBroadcaster(config, logger)
+	gsm = guard.NewGuardState(logger, config, func() {
+		txMu.Lock()
+		defer txMu.Unlock()
+		if txData == nil {
+			logger.Error("set_offline transaction is null")
+			gsm.AuditBroadcast("set_offline", nil, "set_offline transaction is null")
+			return
+		}
+		report := broadcaster.Broadcast(txData)
+		gsm.NotifySetOffline(report)
+		if !report.Accepted() {
+			// no node got transaction, keep it for next trigger
+			return
+		}
+		// transaction can be used only once
+		txData = nil
+		for _, w := range watchers {
+			w.SetTxData(nil)
+		}
+	})
+	if config.AuditLog > "" {
+		audit, err := guard.NewAuditLog(config.AuditLog, config.AuditMaxSize, config.AuditMaxFiles)
+		if err != nil {
+			return fmt.Errorf("AUDIT_LOG: %s", err.Error())
+		}
+		defer audit.Close()
+		gsm.SetAudit(audit)
+	}
+	notifier, err := notify.NewFromConfig(config, gsm, logger)
+	if err != nil {
+		return fmt.Errorf("can't configure notifications: %s", err.Error())
+	}
+	notifier.Start()
+	if notifier.Senders() > 0 {
+		gsm.SetNotifier(notifier)
+	}
+
+	wg.Add(1)
+	go func() {
+		gsm.Start()
+		wg.Done()
+	}()
+	if config.DigestPeriod > "" {
+		wg.Add(1)
+		go func() {
+			gsm.RunDigest(config.DigestPeriod)
+			wg.Done()
+		}()
+	}
+
+	metrics := guard.NewMetrics(gsm)
+
+	nodes := strings.Split(config.NodesEndpoints, ",")
+	for _, node := range nodes {
+		w := guard.NewWatcher(
+			node,
+			config,
+			gsm,
+			logger,
+			exclusiveCheck,
+		)
+		w.SetTxData(txData)
+		w.SetMetrics(metrics)
+		wg.Add(1)
+		go func() {
+			w.Start()
+			wg.Done()
+		}()
+		watchers = append(watchers, w)
+	}
+
+	if config.HttpListener > "" {
+		httpServer = &http.Server{
+			Addr:        config.HttpListener,
+			Handler:     nil, // default http mux
+			ReadTimeout: 5 * time.Second,
+		}
+		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Write(gsm.GetJsonStatus())
+		})
+		http.HandleFunc("/dashboard", serveDashboard(gsm, config))
+		http.HandleFunc("/watchers", func(w http.ResponseWriter, r *http.Request) {
+			infos := []guard.WatcherInfo{}
+			for _, watcher := range watchers {
+				infos = append(infos, watcher.Info())
+			}
+			writeJson(w, http.StatusOK, infos)
+		})
+		http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			if err := gsm.Liveness(time.Now()); err != nil {
+				writeJson(w, http.StatusServiceUnavailable, map[string]string{"status": "error", "error": err.Error()})
+				return
+			}
+			writeJson(w, http.StatusOK, map[string]string{"status": "ok"})
+		})
+		http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+			problems := gsm.Readiness(time.Now())
+			if len(problems) > 0 {
+				writeJson(w, http.StatusServiceUnavailable, map[string]interface{}{"ready": false, "reasons": problems})
+				return
+			}
+			writeJson(w, http.StatusOK, map[string]interface{}{"ready": true, "reasons": []string{}})
+		})
+		http.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			filter, err := guard.ParseHistoryFilter(query.Get("since"), query.Get("type"), query.Get("validator"))
+			if err != nil {
+				writeJson(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJson(w, http.StatusOK, gsm.Events(filter))
+		})
+		stream := newEventStream(gsm)
+		httpServer.RegisterOnShutdown(stream.Close)
+		http.Handle("/events/stream", stream)
+		http.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+		http.HandleFunc("/digest", func(w http.ResponseWriter, r *http.Request) {
+			writeJson(w, http.StatusOK, map[string]guard.DigestReport{
+				"current":  gsm.Digest(),
+				"previous": gsm.LastDigest(),
+			})
+		})
+		if config.AdminToken > "" {
+			admin := &adminApi{
+				config:      config,
+				operator:    operator,
+				gsm:         gsm,
+				broadcaster: broadcaster,
+				setTxData:   setTxData,
+				logger:      logger,
+			}
+			admin.register(http.DefaultServeMux)
+		}
+		wg.Add(1)
+		go func() {
+			err := httpServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				logger.Error("error in http.ListenAndServe", "err", err.Error())
+			}
+			wg.Done()
+		}()
+	}
+
+	exit := make(chan os.Signal, 1)
+	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
+
+	// wait for stop/restart/etc
+	<-exit
+
+	// graceful shotdown
+	for _, w := range watchers {
+		w.Stop()
+	}
+	gsm.Stop()
+	if httpServer != nil {
+		httpServer.Shutdown(context.Background())
+	}
+
+	wg.Wait()
+	notifier.Stop()
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/keys"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+// newSetOnlineCmd checks validator node, builds (or takes pre-signed) set_online transaction
+// and after confirmation sends it through running guard admin API or directly to nodes
+func newSetOnlineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-online",
+		Short: "Set validator online after incident and re-arm guard",
+		Args:  cobra.NoArgs,
+	}
+	txHex := cmd.Flags().String("tx", "", "pre-signed set_online transaction in hex (signing key is used if empty)")
+	offlineTxHex := cmd.Flags().String("offline-tx", "", "pre-signed next set_offline transaction in hex to re-arm guard")
+	feeStr := cmd.Flags().String("fee", "0del", "transaction fee")
+	guardUrl := cmd.Flags().String("guard", "", "guard admin API url (default http://HTTP_LISTENER, 'none' to broadcast directly)")
+	yes := cmd.Flags().Bool("yes", false, "broadcast without interactive confirmation")
+	var keyOpts keys.Options
+	keyFlags := flag.NewFlagSet("keys", flag.ContinueOnError)
+	keys.BindFlags(keyFlags, &keyOpts)
+	cmd.Flags().AddGoFlagSet(keyFlags)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		config, v, err := loadConfig(cmd, true)
+		if err != nil {
+			return err
+		}
+		if config.ValidatorNode == "" {
+			return fmt.Errorf("VALIDATOR_NODE is not configured")
+		}
+		// stdin is shared by mnemonic (key source 'stdin') and confirmation
+		input := bufio.NewReader(cmd.InOrStdin())
+		keyOpts.Input = input
+
+		// 1. validator node must be ready to sign blocks
+		status, err := guard.CheckValidatorNode(config.ValidatorNode, config)
+		if err != nil {
+			return fmt.Errorf("validator node is not ready: %s", err.Error())
+		}
+		fmt.Printf("validator node %s is synced, height %d, chain-id %s\n", config.ValidatorNode, status.LatestBlockHeight, status.Network)
+
+		// 2. set_online transaction and next set_offline transaction
+		if *txHex == "" {
+			keyOpts.EnvMnemonic = v.GetString("MNEMONIC")
+			*txHex, *offlineTxHex, err = buildSetOnline(config, status.Network, keyOpts, *feeStr)
+			if err != nil {
+				return fmt.Errorf("can't build transactions: %s", err.Error())
+			}
+		}
+		tx, info, err := decodeTxHex(*txHex, txdata.TypeMsgSetOnline)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("set_online: validator %s, signer %s, sequence %d\n", info.Validator, info.Signer, info.Sequence)
+		if *offlineTxHex == "" {
+			fmt.Printf("WARNING: next set_offline transaction is not provided, guard will stay without protection\n")
+		}
+
+		// 3. explicit confirmation
+		if !*yes && !confirm(input, "broadcast set_online transaction?") {
+			fmt.Printf("cancelled\n")
+			return nil
+		}
+
+		// 4. through guard: it broadcasts, starts grace period and takes new set_offline
+		if *guardUrl == "" && config.HttpListener > "" {
+			*guardUrl = "http://" + config.HttpListener
+		}
+		if *guardUrl != "" && *guardUrl != "none" {
+			if err := sendSetOnline(*guardUrl, config.AdminToken, *txHex, *offlineTxHex); err != nil {
+				return fmt.Errorf("guard admin API: %s", err.Error())
+			}
+			return nil
+		}
+
+		// 4. without guard
+		broadcaster := guard.NewBroadcaster(config, tmlog.NewTMLogger(os.Stdout))
+		report := broadcaster.Broadcast(tx)
+		if !report.Included {
+			return fmt.Errorf("set_online transaction is not included in block")
+		}
+		fmt.Printf("set_online transaction %s is in block %d\n", report.TxHash, report.Height)
+		if *offlineTxHex > "" {
+			fmt.Printf("update SET_OFFLINE_TX and restart guard:\nSET_OFFLINE_TX=%s\n", *offlineTxHex)
+		}
+		return nil
+	}
+	return cmd
+}
+
+// buildSetOnline signs set_online with current account sequence and set_offline with next one
+func buildSetOnline(config guard.Config, chainID string, keyOpts keys.Options, feeStr string) (string, string, error) {
+	fee, err := sdk.ParseCoinNormalized(feeStr)
+	if err != nil {
+		return "", "", fmt.Errorf("can't parse fee: %s", err.Error())
+	}
+	signer, err := keys.Load(keyOpts)
+	if err != nil {
+		return "", "", fmt.Errorf("can't load signing key: %s", err.Error())
+	}
+	address, err := txdata.SignerAddress(signer)
+	if err != nil {
+		return "", "", err
+	}
+	client := fastclient.NewFastClient(config.ValidatorNode, time.Duration(config.NewBlockTimeout)*time.Second)
+	account, err := txdata.QueryAccount(client, address)
+	if err != nil {
+		return "", "", err
+	}
+	data := txdata.SignerData{ChainID: chainID, AccountNumber: account.AccountNumber, Sequence: account.Sequence}
+	onlineTx, err := txdata.BuildSetOnline(signer, data, fee)
+	if err != nil {
+		return "", "", err
+	}
+	data.Sequence++
+	offlineTx, err := txdata.BuildSetOffline(signer, data, fee)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(onlineTx), hex.EncodeToString(offlineTx), nil
+}
+
+func confirm(input *bufio.Reader, question string) bool {
+	fmt.Printf("%s type 'yes' to continue: ", question)
+	answer, _ := input.ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
+
+// sendSetOnline sends transactions to admin set-online endpoint of running guard
+func sendSetOnline(guardUrl, token, txHex, offlineTxHex string) error {
+	bz, err := json.Marshal(setOnlineRequest{Tx: txHex, OfflineTx: offlineTxHex, Confirm: true})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(guardUrl, "/")+"/admin/set-online", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	// guard waits for transaction in block
+	client := http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http code %d", resp.StatusCode)
+	}
+	return nil
+}
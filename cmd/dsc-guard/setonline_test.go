@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendSetOnline(t *testing.T) {
+	var txData []byte
+	srv := adminServer(t, &txData)
+	defer srv.Close()
+
+	err := sendSetOnline(srv.URL, "wrong", testTx(t, testMnemonic, true, 2), "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "http code 401")
+	// request is decoded and checked by guard: validator node is not configured
+	err = sendSetOnline(srv.URL+"/", "secret", testTx(t, testMnemonic, true, 2), testTx(t, testMnemonic, false, 3))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "http code 409")
+	require.Nil(t, txData)
+}
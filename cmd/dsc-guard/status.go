@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Print status of running guard",
+		Args:  cobra.NoArgs,
+	}
+	guardUrl := cmd.Flags().String("guard", "", "url of running guard (default http://HTTP_LISTENER)")
+	asJson := cmd.Flags().Bool("json", false, "print status as JSON")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		status, err := queryStatus(cmd, *guardUrl)
+		if err != nil {
+			return err
+		}
+		if *asJson {
+			return printJson(status)
+		}
+		fmt.Printf("state: %s\n", status.State)
+		if status.Critical > "" {
+			fmt.Printf("critical: %s\n", status.Critical)
+		}
+		fmt.Printf("validator online: %v\n", status.ValidatorOnline)
+		fmt.Printf("set_offline transaction: %s\n", status.TransactionStatus)
+		if status.TransactionInvalidReason > "" {
+			fmt.Printf("invalid reason: %s\n", status.TransactionInvalidReason)
+		}
+		if status.TransactionError > "" {
+			fmt.Printf("transaction error: %s\n", status.TransactionError)
+		}
+		fmt.Printf("watchers: %d of %d watching\n", status.WatchersWatching, status.WatchersCount)
+		fmt.Printf("height: %d\n", status.CurrentHeight)
+		fmt.Printf("missed blocks: %d of %d (limit %d)", status.Window.Missed, status.Window.Size, status.Window.Limit)
+		if status.Degraded {
+			fmt.Printf(", degraded")
+		}
+		fmt.Println()
+		if status.GracePeriodUntil > 0 {
+			fmt.Printf("grace period until: %d\n", status.GracePeriodUntil)
+		}
+		return nil
+	}
+	return cmd
+}
+
+func newWindowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "window",
+		Short: "Print current sign window of running guard",
+		Args:  cobra.NoArgs,
+	}
+	guardUrl := cmd.Flags().String("guard", "", "url of running guard (default http://HTTP_LISTENER)")
+	asJson := cmd.Flags().Bool("json", false, "print window as JSON")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		status, err := queryStatus(cmd, *guardUrl)
+		if err != nil {
+			return err
+		}
+		ws := status.Window
+		if *asJson {
+			return printJson(ws)
+		}
+		fmt.Printf("height %d, missed %d of %d blocks (limit %d), %d in a row, last signed %d\n",
+			ws.Height, ws.Missed, ws.Size, ws.Limit, ws.Streak, ws.LastSigned)
+		fmt.Println(ws.Marks())
+		var missed []string
+		for _, b := range ws.Blocks {
+			if b.Sign == guard.BlockMissed {
+				missed = append(missed, fmt.Sprint(b.Height))
+			}
+		}
+		if len(missed) > 0 {
+			fmt.Printf("missed: %s\n", strings.Join(missed, ", "))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// queryStatus gets status from running guard, url is taken from HTTP_LISTENER if it is not given
+func queryStatus(cmd *cobra.Command, guardUrl string) (guard.GuardStatus, error) {
+	var status guard.GuardStatus
+	if guardUrl == "" {
+		config, _, err := loadConfig(cmd, false)
+		if err != nil {
+			return status, err
+		}
+		if config.HttpListener == "" {
+			return status, fmt.Errorf("guard url is not set: use --guard, --http-listener or HTTP_LISTENER")
+		}
+		guardUrl = "http://" + config.HttpListener
+		if strings.HasPrefix(config.HttpListener, ":") {
+			guardUrl = "http://localhost" + config.HttpListener
+		}
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(strings.TrimRight(guardUrl, "/") + "/")
+	if err != nil {
+		return status, fmt.Errorf("query guard: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return status, fmt.Errorf("query guard: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return status, fmt.Errorf("query guard: http code %d: %s", resp.StatusCode, body)
+	}
+	err = json.Unmarshal(body, &status)
+	if err != nil {
+		return status, fmt.Errorf("decode guard status: %s", err.Error())
+	}
+	if status.Version != guard.StatusVersion {
+		fmt.Fprintf(os.Stderr, "warning: guard status version %d, expected %d\n", status.Version, guard.StatusVersion)
+	}
+	return status, nil
+}
+
+func printJson(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// StreamKeepAlive is interval of comments sent to idle stream, it keeps connection through proxies
+const StreamKeepAlive = 15 * time.Second
+
+// eventStream pushes guard events to clients as Server-Sent Events
+type eventStream struct {
+	gsm  *guard.GuardStateMachine
+	done chan struct{} // closed on server shutdown
+}
+
+func newEventStream(gsm *guard.GuardStateMachine) *eventStream {
+	return &eventStream{gsm: gsm, done: make(chan struct{})}
+}
+
+// Close finishes all streams, http.Server.Shutdown waits for them
+func (s *eventStream) Close() {
+	close(s.done)
+}
+
+// ServeHTTP streams events accepted by filter (since, type, validator), events from history are sent first
+// if since or Last-Event-ID (reconnect of EventSource) is set
+func (s *eventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJson(w, http.StatusInternalServerError, map[string]string{"error": "streaming is not supported"})
+		return
+	}
+	query := r.URL.Query()
+	since := query.Get("since")
+	if lastID := r.Header.Get("Last-Event-ID"); lastID > "" {
+		since = lastID
+	}
+	filter, err := guard.ParseHistoryFilter(since, query.Get("type"), query.Get("validator"))
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// subscribe before reading history to not lose events between them
+	events, unsubscribe := s.gsm.SubscribeEvents(guard.HistoryFilter{Types: filter.Types, Validator: filter.Validator})
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	var lastID uint64
+	if since > "" {
+		for _, ev := range s.gsm.Events(filter) {
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+			lastID = ev.ID
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(StreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case ev := <-events:
+			if ev.ID <= lastID {
+				continue
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev guard.HistoryEvent) error {
+	bz, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", strconv.FormatUint(ev.ID, 10), ev.Type, bz)
+	return err
+}
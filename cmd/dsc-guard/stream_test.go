@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// readEventIDs reads ids of n events from stream, keep-alive comments are skipped
+func readEventIDs(t *testing.T, reader *bufio.Reader, n int) []uint64 {
+	var ids []uint64
+	for len(ids) < n {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "id: ") {
+			id, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "id: ")), 10, 64)
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestEventStream(t *testing.T) {
+	gsm := guard.NewGuardState(tmlog.NewTMLogger(dummyWriter{}), guard.Config{MissedBlocksLimit: 8, MissedBlocksWindow: 24}, nil)
+	go gsm.Start()
+	defer gsm.Stop()
+	require.Eventually(t, func() bool { return gsm.Liveness(time.Now()) == nil }, 5*time.Second, 10*time.Millisecond)
+	gsm.ReportWatcher("a", guard.WatcherConnecting)
+	gsm.ReportWatcherError("a", fmt.Errorf("connection refused"))
+	gsm.ReportWatcher("a", guard.WatcherWatching)
+	require.Eventually(t, func() bool {
+		return len(gsm.Events(guard.HistoryFilter{Types: []string{guard.HistoryWatcherState}})) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	history := gsm.Events(guard.HistoryFilter{})
+
+	stream := newEventStream(gsm)
+	defer stream.Close()
+	srv := httptest.NewServer(stream)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?since=yesterday")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// reconnect: Last-Event-ID overrides since, events after it are replayed
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?since=0", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(history[0].ID, 10))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+	replayed := readEventIDs(t, reader, len(history)-1)
+	for i, id := range replayed {
+		require.Equal(t, history[i+1].ID, id)
+	}
+
+	// new event is sent once after replayed events
+	gsm.ReportWatcherOk("a")
+	next := readEventIDs(t, reader, 1)
+	require.Equal(t, history[len(history)-1].ID+1, next[0])
+	last := gsm.Events(guard.HistoryFilter{SinceID: history[len(history)-1].ID})
+	require.Len(t, last, 1)
+	require.Equal(t, guard.HistoryWatcherOk, last[0].Type)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+func newCheckTxCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-tx [tx]",
+		Short: "Check set_offline transaction (argument, --set-offline-tx or SET_OFFLINE_TX) on every node",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, _, err := loadConfig(cmd, false)
+			if err != nil {
+				return err
+			}
+			txData, err := txFromArgs(args, config)
+			if err != nil {
+				return err
+			}
+			if config.NodesEndpoints == "" {
+				return fmt.Errorf("nodes are not set: use --nodes or NODES_ENDPOINTS")
+			}
+			return checkTx(os.Stdout, config.NodesEndpoints, txData)
+		},
+	}
+}
+
+// checkTx prints verdict of every node, error is returned if any node rejects transaction or no node gives verdict
+func checkTx(w io.Writer, nodes string, txData []byte) error {
+	invalid, failed, total := 0, 0, 0
+	for _, node := range strings.Split(nodes, ",") {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		total++
+		client := fastclient.NewFastClient(node, 10*time.Second)
+		res, err := client.CheckTx(txData)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "%s: error: %s\n", node, err.Error())
+			continue
+		}
+		if res.Code == 0 {
+			fmt.Fprintf(w, "%s: valid\n", node)
+			continue
+		}
+		invalid++
+		reason, details, err := guard.DiagnoseTx(client, txData, res, false)
+		fmt.Fprintf(w, "%s: invalid: %s: %s\n", node, guard.TxReasonName(reason), details)
+		if err != nil {
+			fmt.Fprintf(w, "%s: diagnosis is incomplete: %s\n", node, err.Error())
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("transaction is invalid on %d nodes", invalid)
+	}
+	if failed == total {
+		return fmt.Errorf("transaction is not checked: all %d nodes failed", failed)
+	}
+	return nil
+}
+
+func newDecodeTxCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decode-tx [tx]",
+		Short: "Decode transaction (argument, --set-offline-tx or SET_OFFLINE_TX)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, _, err := loadConfig(cmd, false)
+			if err != nil {
+				return err
+			}
+			txData, err := txFromArgs(args, config)
+			if err != nil {
+				return err
+			}
+			info, err := txdata.Decode(txData)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("messages: %s\n", strings.Join(info.Messages, ", "))
+			fmt.Printf("validator: %s\n", info.Validator)
+			fmt.Printf("signer: %s\n", info.Signer)
+			fmt.Printf("sequence: %d\n", info.Sequence)
+			fmt.Printf("fee: %s\n", info.Fee)
+			fmt.Printf("gas limit: %d\n", info.GasLimit)
+			if info.Memo > "" {
+				fmt.Printf("memo: %s\n", info.Memo)
+			}
+			return nil
+		},
+	}
+}
+
+// txFromArgs decodes transaction hex from argument or configuration
+func txFromArgs(args []string, config guard.Config) ([]byte, error) {
+	txHex := config.SetOfflineTx
+	if len(args) > 0 {
+		txHex = args[0]
+	}
+	txHex = strings.TrimSpace(txHex)
+	if txHex == "" {
+		return nil, fmt.Errorf("transaction is not set: use argument, --set-offline-tx or SET_OFFLINE_TX")
+	}
+	txData, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode tx data: %s", err.Error())
+	}
+	return txData, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTx(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/check_tx", r.URL.Path)
+		w.Write([]byte(`{"result":{"code":0,"log":""}}`))
+	}))
+	defer node.Close()
+	down := "http://127.0.0.1:1"
+
+	var out bytes.Buffer
+	require.NoError(t, checkTx(&out, node.URL+","+down, []byte{1, 2, 3}))
+	require.Contains(t, out.String(), node.URL+": valid")
+	require.Contains(t, out.String(), down+": error")
+
+	// no node gave verdict
+	out.Reset()
+	err := checkTx(&out, down+", "+down, []byte{1, 2, 3})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "all 2 nodes failed")
+}
@@ -1,11 +1,14 @@
 package fastclient
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 	"time"
 )
@@ -46,6 +49,33 @@ func (e RpcError) Error() string {
 	return e.Data
 }
 
+// Observer gets duration of every RPC request, method is last element of URL path (validators, block, ...)
+type Observer func(method string, duration time.Duration, err error)
+
+type observedTransport struct {
+	base    http.RoundTripper
+	observe Observer
+}
+
+func (ot *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := ot.base.RoundTrip(req)
+	method := path.Base(req.URL.Path)
+	if method == "/" || method == "." {
+		method = "root"
+	}
+	ot.observe(method, time.Since(start), err)
+	return resp, err
+}
+
+// SetObserver must be called before requests
+func (fc *FastClient) SetObserver(observe Observer) {
+	if observe == nil {
+		return
+	}
+	fc.conn.Transport = &observedTransport{base: fc.conn.Transport, observe: observe}
+}
+
 func NewFastClient(basePath string, timeout time.Duration) *FastClient {
 	var fc FastClient
 	limitedTransport := http.Transport{
@@ -169,14 +199,20 @@ func (fc *FastClient) CheckTx(tx []byte) (CheckTxResult, error) {
 	return result.Result, nil
 }
 
-func (fc *FastClient) BroadcastTxSync(tx []byte) (CheckTxResult, error) {
+func (fc *FastClient) BroadcastTxSync(ctx context.Context, tx []byte) (CheckTxResult, error) {
 	type rpcResult struct {
+		Error  RpcError      `json:"error"`
 		Result CheckTxResult `json:"result"`
 	}
-	resp, err := fc.conn.Get(fc.basePath + "/broadcast_tx_sync?tx=0x" + hex.EncodeToString(tx))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fc.basePath+"/broadcast_tx_sync?tx=0x"+hex.EncodeToString(tx), nil)
+	if err != nil {
+		return CheckTxResult{}, err
+	}
+	resp, err := fc.conn.Do(req)
 	if err != nil {
 		return CheckTxResult{}, err
 	}
+	defer resp.Body.Close()
 	var result rpcResult
 	bz, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -186,5 +222,142 @@ func (fc *FastClient) BroadcastTxSync(tx []byte) (CheckTxResult, error) {
 	if err != nil {
 		return CheckTxResult{}, err
 	}
+	if result.Error.Code != 0 {
+		return CheckTxResult{}, result.Error
+	}
 	return result.Result, nil
 }
+
+type TxResult struct {
+	Height int64
+	Code   int
+	Log    string
+}
+
+// Tx returns result of transaction included in block, hash is sha256 of transaction bytes
+func (fc *FastClient) Tx(ctx context.Context, hash []byte) (TxResult, error) {
+	type rpcResult struct {
+		Error  RpcError `json:"error"`
+		Result struct {
+			Height   string        `json:"height"`
+			TxResult CheckTxResult `json:"tx_result"`
+		} `json:"result"`
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fc.basePath+"/tx?hash=0x"+hex.EncodeToString(hash), nil)
+	if err != nil {
+		return TxResult{}, err
+	}
+	resp, err := fc.conn.Do(req)
+	if err != nil {
+		return TxResult{}, err
+	}
+	defer resp.Body.Close()
+	var result rpcResult
+	bz, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return TxResult{}, err
+	}
+	err = json.Unmarshal(bz, &result)
+	if err != nil {
+		return TxResult{}, err
+	}
+	if result.Error.Code != 0 {
+		return TxResult{}, result.Error
+	}
+
+	var tr TxResult
+	tr.Height, _ = strconv.ParseInt(result.Result.Height, 10, 64)
+	tr.Code = result.Result.TxResult.Code
+	tr.Log = result.Result.TxResult.Log
+	return tr, nil
+}
+
+type NodeStatus struct {
+	Network           string
+	LatestBlockHeight int64
+	LatestBlockTime   time.Time
+	CatchingUp        bool
+	ValidatorAddress  string
+}
+
+func (fc *FastClient) Status() (NodeStatus, error) {
+	type rpcResult struct {
+		Error  RpcError `json:"error"`
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+			SyncInfo struct {
+				LatestBlockHeight string    `json:"latest_block_height"`
+				LatestBlockTime   time.Time `json:"latest_block_time"`
+				CatchingUp        bool      `json:"catching_up"`
+			} `json:"sync_info"`
+			ValidatorInfo struct {
+				Address string `json:"address"`
+			} `json:"validator_info"`
+		} `json:"result"`
+	}
+	resp, err := fc.conn.Get(fc.basePath + "/status")
+	if err != nil {
+		return NodeStatus{}, err
+	}
+	defer resp.Body.Close()
+	var result rpcResult
+	bz, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return NodeStatus{}, err
+	}
+	err = json.Unmarshal(bz, &result)
+	if err != nil {
+		return NodeStatus{}, err
+	}
+	if result.Error.Code != 0 {
+		return NodeStatus{}, result.Error
+	}
+
+	var ns NodeStatus
+	ns.Network = result.Result.NodeInfo.Network
+	ns.LatestBlockHeight, _ = strconv.ParseInt(result.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	ns.LatestBlockTime = result.Result.SyncInfo.LatestBlockTime
+	ns.CatchingUp = result.Result.SyncInfo.CatchingUp
+	ns.ValidatorAddress = result.Result.ValidatorInfo.Address
+	return ns, nil
+}
+
+type ABCIQueryResult struct {
+	Code      int    `json:"code"`
+	Codespace string `json:"codespace"`
+	Log       string `json:"log"`
+	Value     []byte `json:"value"`
+}
+
+// ABCIQuery performs abci_query, data is raw protobuf-encoded request
+func (fc *FastClient) ABCIQuery(path string, data []byte) (ABCIQueryResult, error) {
+	type rpcResult struct {
+		Error  RpcError `json:"error"`
+		Result struct {
+			Response ABCIQueryResult `json:"response"`
+		} `json:"result"`
+	}
+	query := url.Values{}
+	query.Set("path", strconv.Quote(path))
+	query.Set("data", "0x"+hex.EncodeToString(data))
+	resp, err := fc.conn.Get(fc.basePath + "/abci_query?" + query.Encode())
+	if err != nil {
+		return ABCIQueryResult{}, err
+	}
+	defer resp.Body.Close()
+	var result rpcResult
+	bz, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ABCIQueryResult{}, err
+	}
+	err = json.Unmarshal(bz, &result)
+	if err != nil {
+		return ABCIQueryResult{}, err
+	}
+	if result.Error.Code != 0 {
+		return ABCIQueryResult{}, result.Error
+	}
+	return result.Result.Response, nil
+}
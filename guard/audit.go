@@ -0,0 +1,307 @@
+package guard
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaults of audit log rotation
+const (
+	DefaultAuditMaxSize  = 10 // megabytes
+	DefaultAuditMaxFiles = 5  // rotated files kept besides current one
+)
+
+// types of audit records
+const (
+	AuditTransition = "transition"
+	AuditBroadcast  = "broadcast"
+)
+
+// AuditInputs are values which state machine used to choose next state
+type AuditInputs struct {
+	Event           string       `json:"event"` // event which caused transition
+	WatcherState    string       `json:"watcher_state"`
+	TxValidity      string       `json:"tx_validity"`
+	TxReason        string       `json:"tx_invalid_reason,omitempty"`
+	TxError         string       `json:"tx_error,omitempty"`
+	ValidatorOnline bool         `json:"validator_online"`
+	SkipSign        bool         `json:"skip_sign"`
+	GraceUntil      int64        `json:"grace_until,omitempty"`
+	Window          WindowStatus `json:"window"`
+}
+
+// AuditRecord is one line of audit log: transition of state machine or broadcast of transaction
+type AuditRecord struct {
+	Time      time.Time        `json:"time"`
+	Type      string           `json:"type"`
+	Validator string           `json:"validator"`
+	Height    int64            `json:"height"`
+	From      string           `json:"from,omitempty"`
+	To        string           `json:"to,omitempty"`
+	Inputs    *AuditInputs     `json:"inputs,omitempty"`
+	Tx        string           `json:"tx,omitempty"` // set_offline or set_online
+	Broadcast *BroadcastReport `json:"broadcast,omitempty"`
+	Error     string           `json:"error,omitempty"` // broadcast was not done
+}
+
+// AuditLog is append-only JSONL file, file is rotated to path.1, path.2... when it reaches max size.
+// Nil *AuditLog is valid and does nothing.
+type AuditLog struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File // nil if file can't be reopened after rotation, it is opened again on next write
+	size     int64
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewAuditLog opens audit log for appending, maxSize is in megabytes
+func NewAuditLog(path string, maxSize int, maxFiles int) (*AuditLog, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultAuditMaxSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultAuditMaxFiles
+	}
+	al := &AuditLog{path: path, maxSize: int64(maxSize) << 20, maxFiles: maxFiles}
+	if err := al.open(); err != nil {
+		return nil, err
+	}
+	return al, nil
+}
+
+func (al *AuditLog) open() error {
+	file, err := os.OpenFile(al.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("open audit log: %s", err.Error())
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open audit log: %s", err.Error())
+	}
+	al.file = file
+	al.size = info.Size()
+	return nil
+}
+
+// rotate renames path.N-1 to path.N ... path to path.1, the oldest file is removed.
+// If path can't be renamed, it is reopened and rotation is retried on next write.
+func (al *AuditLog) rotate() error {
+	al.file.Close()
+	al.file = nil
+	os.Remove(fmt.Sprintf("%s.%d", al.path, al.maxFiles))
+	for i := al.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", al.path, i), fmt.Sprintf("%s.%d", al.path, i+1))
+	}
+	if err := os.Rename(al.path, al.path+".1"); err != nil {
+		if err := al.open(); err != nil {
+			return err
+		}
+		return fmt.Errorf("rotate audit log: %s", err.Error())
+	}
+	return al.open()
+}
+
+// Write appends record and syncs file: record must survive crash of guard.
+// Record is appended to current file if rotation fails, error of rotation is returned then.
+func (al *AuditLog) Write(record AuditRecord) error {
+	if al == nil {
+		return nil
+	}
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode audit record: %s", err.Error())
+	}
+	bz = append(bz, '\n')
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if al.closed {
+		return fmt.Errorf("audit log is closed")
+	}
+	if al.file == nil {
+		if err := al.open(); err != nil {
+			return err
+		}
+	}
+	var rotateErr error
+	if al.size > 0 && al.size+int64(len(bz)) > al.maxSize {
+		if rotateErr = al.rotate(); al.file == nil {
+			return rotateErr
+		}
+	}
+	n, err := al.file.Write(bz)
+	al.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write audit log: %s", err.Error())
+	}
+	if err := al.file.Sync(); err != nil {
+		return err
+	}
+	return rotateErr
+}
+
+func (al *AuditLog) Close() error {
+	if al == nil {
+		return nil
+	}
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	al.closed = true
+	if al.file == nil {
+		return nil
+	}
+	err := al.file.Close()
+	al.file = nil
+	return err
+}
+
+// SetAudit must be called before Start
+func (sm *GuardStateMachine) SetAudit(audit *AuditLog) {
+	sm.audit = audit
+}
+
+// writeAudit is called under state lock
+func (sm *GuardStateMachine) writeAudit(record AuditRecord) {
+	if sm.audit == nil {
+		return
+	}
+	record.Time = time.Now()
+	record.Validator = sm.config.ValidatorAddress
+	record.Height = sm.currentHeight
+	if err := sm.audit.Write(record); err != nil {
+		sm.logger.Error("audit log", "err", err.Error())
+	}
+}
+
+// auditTransition records transition with inputs of state machine
+func (sm *GuardStateMachine) auditTransition(from, to GlobalState) {
+	if sm.audit == nil {
+		return
+	}
+	txValidity, txReason, txError := sm.txStatus()
+	sm.writeAudit(AuditRecord{
+		Type: AuditTransition,
+		From: StateName(from),
+		To:   StateName(to),
+		Inputs: &AuditInputs{
+			Event:           sm.lastEvent,
+			WatcherState:    WatcherStateName(sm.summaryWatcherState()),
+			TxValidity:      txValidity,
+			TxReason:        txReason,
+			TxError:         txError,
+			ValidatorOnline: sm.summaryValidatorOnline(),
+			SkipSign:        sm.isSkipSign,
+			GraceUntil:      sm.graceUntil,
+			Window:          sm.SignWindow(),
+		},
+	})
+}
+
+// AuditBroadcast records broadcast of transaction, report is nil if transaction was not broadcasted.
+// It must not be called from event processing: state lock is taken to read current height.
+func (sm *GuardStateMachine) AuditBroadcast(tx string, report *BroadcastReport, reason string) {
+	sm.stateMu.Lock()
+	defer sm.stateMu.Unlock()
+	sm.writeAudit(AuditRecord{Type: AuditBroadcast, Tx: tx, Broadcast: report, Error: reason})
+}
+
+// eventName is name of state machine event for audit: eventWatcherState -> watcher_state
+func eventName(ev interface{}) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", ev), "guard.event")
+	var sb strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// ReadAudit calls fn for every record of audit log, reading stops on first error of fn
+func ReadAudit(r io.Reader, fn func(record AuditRecord) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// FormatAudit returns human readable text of audit record
+func FormatAudit(record AuditRecord) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s  height %d  ", record.Time.UTC().Format("2006-01-02 15:04:05 MST"), record.Height)
+	switch record.Type {
+	case AuditTransition:
+		fmt.Fprintf(&sb, "%s -> %s", record.From, record.To)
+		if in := record.Inputs; in != nil {
+			fmt.Fprintf(&sb, " (event %s)\n", in.Event)
+			tx := in.TxValidity
+			if in.TxReason > "" {
+				tx += " (" + in.TxReason + ")"
+			}
+			fmt.Fprintf(&sb, "    watchers: %s, tx: %s, validator online: %v, skip sign: %v", in.WatcherState, tx, in.ValidatorOnline, in.SkipSign)
+			if in.GraceUntil > 0 {
+				fmt.Fprintf(&sb, ", grace until %d", in.GraceUntil)
+			}
+			if in.TxError > "" {
+				fmt.Fprintf(&sb, "\n    tx error: %s", in.TxError)
+			}
+			ws := in.Window
+			fmt.Fprintf(&sb, "\n    window: missed %d of %d (limit %d), %d in a row, last signed %d\n    %s",
+				ws.Missed, ws.Size, ws.Limit, ws.Streak, ws.LastSigned, ws.Marks())
+		}
+	case AuditBroadcast:
+		fmt.Fprintf(&sb, "broadcast %s", record.Tx)
+		if record.Error > "" {
+			fmt.Fprintf(&sb, ": not sent: %s", record.Error)
+		}
+		if b := record.Broadcast; b != nil {
+			if b.Included {
+				fmt.Fprintf(&sb, ": included in block %d (reported by %s)", b.Height, b.Node)
+			} else if b.Failed {
+				fmt.Fprintf(&sb, ": failed in block %d (reported by %s)", b.Height, b.Node)
+			} else if b.Accepted() {
+				sb.WriteString(": accepted, not included in block")
+			} else {
+				sb.WriteString(": rejected by all nodes")
+			}
+			fmt.Fprintf(&sb, ", tx %s, %s", b.TxHash, b.Duration.Round(time.Millisecond))
+			for _, o := range b.Outcomes {
+				fmt.Fprintf(&sb, "\n    %s: attempts %d, accepted %v, included %v, failed %v", o.Node, o.Attempts, o.Accepted, o.Included, o.Failed)
+				if o.Code != 0 {
+					fmt.Fprintf(&sb, ", code %d, log %s", o.Code, o.Log)
+				}
+				if o.Error > "" {
+					fmt.Fprintf(&sb, ", error %s", o.Error)
+				}
+			}
+		}
+	default:
+		sb.WriteString(record.Type)
+	}
+	return sb.String()
+}
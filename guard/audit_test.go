@@ -0,0 +1,77 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(path, 1, 2)
+	require.NoError(t, err)
+	defer audit.Close()
+
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 2, MissedBlocksWindow: 4, ValidatorAddress: "AA"}, nil)
+	gsm.SetAudit(audit)
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventValidatorState{"a", 1, true})
+	gsm.ProcessEvent(eventTxValidity{"a", true})
+	gsm.NotifySetOffline(BroadcastReport{TxHash: "ABC", Included: true, Height: 2, Node: "a",
+		Outcomes: []BroadcastOutcome{{Node: "a", Attempts: 1, Accepted: true, Included: true}}})
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var records []AuditRecord
+	require.NoError(t, ReadAudit(file, func(record AuditRecord) error {
+		records = append(records, record)
+		return nil
+	}))
+	require.Len(t, records, 4)
+	last := records[2]
+	require.Equal(t, AuditTransition, last.Type)
+	require.Equal(t, "watching", last.To)
+	require.Equal(t, "tx_validity", last.Inputs.Event)
+	require.Equal(t, "valid", last.Inputs.TxValidity)
+	require.Equal(t, "AA", last.Validator)
+	require.True(t, strings.Contains(FormatAudit(last), "watching_without_tx -> watching (event tx_validity)"), FormatAudit(last))
+	require.True(t, strings.Contains(FormatAudit(records[3]), "broadcast set_offline: included in block 2 (reported by a)"), FormatAudit(records[3]))
+
+	// rotation: current file is moved to .1 when it is full
+	audit.maxSize = 1
+	require.NoError(t, audit.Write(AuditRecord{Type: AuditBroadcast, Error: "set_offline transaction is null"}))
+	require.NoError(t, audit.Write(AuditRecord{Type: AuditBroadcast, Error: "set_offline transaction is null"}))
+	require.NoError(t, audit.Write(AuditRecord{Type: AuditBroadcast, Error: "set_offline transaction is null"}))
+	require.FileExists(t, path+".1")
+	require.FileExists(t, path+".2")
+	require.NoFileExists(t, path+".3")
+}
+
+func TestAuditLogRotateFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(path, 1, 1)
+	require.NoError(t, err)
+	defer audit.Close()
+	audit.maxSize = 1
+	require.NoError(t, audit.Write(AuditRecord{Type: AuditBroadcast, Tx: "set_offline"}))
+
+	// path.1 is not empty directory: rename fails, record is appended to current file
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "dir"), 0755))
+	require.Error(t, audit.Write(AuditRecord{Type: AuditBroadcast, Tx: "set_online"}))
+	bz, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(bz), "\n"))
+
+	// rotation is retried on next write
+	require.NoError(t, os.RemoveAll(path+".1"))
+	require.NoError(t, audit.Write(AuditRecord{Type: AuditBroadcast, Tx: "set_offline"}))
+	require.FileExists(t, path+".1")
+	bz, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(bz), "\n"))
+}
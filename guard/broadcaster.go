@@ -0,0 +1,196 @@
+package guard
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+)
+
+const DefaultBroadcastTimeout = 30 // seconds
+const BroadcastRetryPause = time.Second
+
+// Broadcaster sends transaction to all endpoints at the same time, retries transient errors until deadline
+// and stops when any node reports transaction in block or all nodes reject it
+type Broadcaster struct {
+	endpoints      []string
+	timeout        time.Duration
+	requestTimeout time.Duration
+	retryPause     time.Duration
+	logger         tmlog.Logger
+}
+
+// BroadcastOutcome is result of broadcasting for single node
+type BroadcastOutcome struct {
+	Node     string `json:"node"`
+	Attempts int    `json:"attempts"`
+	Accepted bool   `json:"accepted"`         // transaction is in node mempool
+	Included bool   `json:"included"`         // node reported transaction in block
+	Failed   bool   `json:"failed,omitempty"` // transaction is in block, but DeliverTx failed
+	Height   int64  `json:"height,omitempty"`
+	Code     int    `json:"code,omitempty"`
+	Log      string `json:"log,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BroadcastReport struct {
+	TxHash   string             `json:"tx_hash"`
+	Included bool               `json:"included"`
+	Failed   bool               `json:"failed,omitempty"` // transaction is in block, but DeliverTx failed
+	Height   int64              `json:"height,omitempty"`
+	Node     string             `json:"node,omitempty"` // first node which reported transaction in block
+	Duration time.Duration      `json:"duration"`
+	Outcomes []BroadcastOutcome `json:"outcomes"`
+}
+
+// NewBroadcaster creates broadcaster for watched nodes and extra broadcast-only endpoints (duplicates are skipped)
+func NewBroadcaster(config Config, logger tmlog.Logger) *Broadcaster {
+	timeout := config.BroadcastTimeout
+	if timeout <= 0 {
+		timeout = DefaultBroadcastTimeout
+	}
+	b := &Broadcaster{
+		timeout:        time.Duration(timeout) * time.Second,
+		requestTimeout: time.Duration(config.NewBlockTimeout) * time.Second,
+		retryPause:     BroadcastRetryPause,
+		logger:         logger,
+	}
+	seen := make(map[string]bool)
+	for _, list := range []string{config.NodesEndpoints, config.BroadcastEndpoints} {
+		for _, node := range strings.Split(list, ",") {
+			node = strings.TrimSpace(node)
+			if node == "" || seen[node] {
+				continue
+			}
+			seen[node] = true
+			b.endpoints = append(b.endpoints, node)
+		}
+	}
+	return b
+}
+
+func (b *Broadcaster) Broadcast(tx []byte) BroadcastReport {
+	hash := sha256.Sum256(tx)
+	report := BroadcastReport{
+		TxHash:   fmt.Sprintf("%X", hash[:]),
+		Outcomes: make([]BroadcastOutcome, len(b.endpoints)),
+	}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	rejected := 0
+	onRejected := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		rejected++
+		if rejected == len(b.endpoints) {
+			// no node has transaction in mempool, it can't get into block
+			cancel()
+		}
+	}
+	for i, node := range b.endpoints {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			outcome := b.broadcastTo(ctx, node, tx, hash[:], onRejected)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Outcomes[i] = outcome
+			if (outcome.Included || outcome.Failed) && report.Node == "" {
+				report.Included = outcome.Included
+				report.Failed = outcome.Failed
+				report.Height = outcome.Height
+				report.Node = node
+				// other nodes don't need to wait anymore
+				cancel()
+			}
+		}(i, node)
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+
+	for _, o := range report.Outcomes {
+		b.logger.Info("broadcast transaction", "node", o.Node, "attempts", o.Attempts, "accepted", o.Accepted, "included", o.Included,
+			"failed", o.Failed, "height", o.Height, "code", o.Code, "log", o.Log, "err", o.Error)
+	}
+	if report.Included {
+		b.logger.Info("transaction included in block", "tx_hash", report.TxHash, "height", report.Height, "node", report.Node)
+	} else if report.Failed {
+		b.logger.Error("transaction failed in block", "tx_hash", report.TxHash, "height", report.Height, "node", report.Node)
+	} else {
+		b.logger.Error("transaction is not included in block", "tx_hash", report.TxHash, "duration", report.Duration.String())
+	}
+	return report
+}
+
+// Accepted returns true if at least one node accepted transaction to mempool
+func (r BroadcastReport) Accepted() bool {
+	for _, o := range r.Outcomes {
+		if o.Accepted || o.Included || o.Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastTo sends transaction to single node until it is accepted, then polls node for inclusion.
+// Transaction rejected by CheckTx is polled too: it may be included through other node; onRejected is called once on rejection.
+func (b *Broadcaster) broadcastTo(ctx context.Context, node string, tx []byte, hash []byte, onRejected func()) BroadcastOutcome {
+	outcome := BroadcastOutcome{Node: node}
+	client := fastclient.NewFastClient(node, b.requestTimeout)
+	rejected := false
+	for {
+		if !outcome.Accepted && !rejected {
+			outcome.Attempts++
+			res, err := client.BroadcastTxSync(ctx, tx)
+			switch {
+			case err != nil && isTxInCache(err):
+				outcome.Accepted = true
+			case err != nil:
+				// network or node error: retry
+				if ctx.Err() == nil {
+					outcome.Error = err.Error()
+				}
+			case res.Code == 0:
+				outcome.Accepted = true
+				outcome.Error = ""
+			case res.Codespace == sdkerrors.RootCodespace && uint32(res.Code) == sdkerrors.ErrMempoolIsFull.ABCICode():
+				outcome.Code, outcome.Log = res.Code, res.Log
+			default:
+				outcome.Code, outcome.Log = res.Code, res.Log
+				rejected = true
+				onRejected()
+			}
+		}
+		if outcome.Accepted || rejected {
+			res, err := client.Tx(ctx, hash)
+			if err == nil {
+				// transaction failed in DeliverTx is in block too, but validator is not set offline
+				outcome.Included = res.Code == 0
+				outcome.Failed = res.Code != 0
+				outcome.Height = res.Height
+				outcome.Code, outcome.Log = res.Code, res.Log
+				return outcome
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return outcome
+		case <-time.After(b.retryPause):
+		}
+	}
+}
+
+func isTxInCache(err error) bool {
+	return strings.Contains(err.Error(), "tx already exists in cache")
+}
@@ -0,0 +1,107 @@
+package guard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+// stubNode accepts transaction after `failures` errors and includes it after `pending` polls
+func stubNode(failures, pending int32) *httptest.Server {
+	var broadcasts, polls int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broadcast_tx_sync":
+			if atomic.AddInt32(&broadcasts, 1) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"result":{"code":0,"log":""}}`))
+		case "/tx":
+			if atomic.AddInt32(&polls, 1) <= pending {
+				w.Write([]byte(`{"error":{"code":-32603,"message":"Internal error","data":"tx not found"}}`))
+				return
+			}
+			w.Write([]byte(`{"result":{"height":"100","tx_result":{"code":0}}}`))
+		}
+	}))
+}
+
+func TestBroadcaster(t *testing.T) {
+	flaky := stubNode(2, 0)
+	defer flaky.Close()
+	slow := stubNode(0, 1000)
+	defer slow.Close()
+
+	b := NewBroadcaster(Config{
+		NodesEndpoints:     flaky.URL + "," + slow.URL,
+		BroadcastEndpoints: "http://127.0.0.1:1," + slow.URL,
+		NewBlockTimeout:    1,
+		BroadcastTimeout:   10,
+	}, tmlog.NewTMLogger(dummyWriter{}))
+	b.retryPause = 0
+	require.Len(t, b.endpoints, 3)
+
+	report := b.Broadcast([]byte{1, 2, 3})
+	require.True(t, report.Included)
+	require.True(t, report.Accepted())
+	require.Equal(t, flaky.URL, report.Node)
+	require.Equal(t, int64(100), report.Height)
+	require.Equal(t, 3, report.Outcomes[0].Attempts)
+	require.True(t, report.Outcomes[1].Accepted)
+	require.False(t, report.Outcomes[1].Included)
+	require.False(t, report.Outcomes[2].Accepted)
+	require.NotEmpty(t, report.Outcomes[2].Error)
+}
+
+func TestBroadcasterRejected(t *testing.T) {
+	// sequence is used: every node rejects transaction
+	rejecting := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/broadcast_tx_sync":
+				w.Write([]byte(`{"result":{"code":32,"codespace":"sdk","log":"account sequence mismatch"}}`))
+			case "/tx":
+				w.Write([]byte(`{"error":{"code":-32603,"message":"Internal error","data":"tx not found"}}`))
+			}
+		}))
+	}
+	a, b := rejecting(), rejecting()
+	defer a.Close()
+	defer b.Close()
+
+	br := NewBroadcaster(Config{NodesEndpoints: a.URL, BroadcastEndpoints: b.URL, NewBlockTimeout: 1, BroadcastTimeout: 30},
+		tmlog.NewTMLogger(dummyWriter{}))
+	br.retryPause = 10 * time.Millisecond
+	report := br.Broadcast([]byte{1, 2, 3})
+	require.False(t, report.Accepted())
+	require.False(t, report.Included)
+	require.Less(t, report.Duration, 5*time.Second)
+	require.Equal(t, 32, report.Outcomes[1].Code)
+}
+
+func TestBroadcasterFailedInBlock(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broadcast_tx_sync":
+			w.Write([]byte(`{"result":{"code":0,"log":""}}`))
+		case "/tx":
+			w.Write([]byte(`{"result":{"height":"100","tx_result":{"code":5,"log":"insufficient funds"}}}`))
+		}
+	}))
+	defer failing.Close()
+
+	b := NewBroadcaster(Config{NodesEndpoints: failing.URL, NewBlockTimeout: 1, BroadcastTimeout: 10}, tmlog.NewTMLogger(dummyWriter{}))
+	report := b.Broadcast([]byte{1, 2, 3})
+	require.False(t, report.Included)
+	require.True(t, report.Failed)
+	require.True(t, report.Accepted())
+	require.Equal(t, int64(100), report.Height)
+	require.Equal(t, 5, report.Outcomes[0].Code)
+	require.Contains(t, FormatAudit(AuditRecord{Type: AuditBroadcast, Tx: "set_offline", Broadcast: &report}), "failed in block 100")
+}
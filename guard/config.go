@@ -2,16 +2,52 @@ package guard
 
 // Config is an object containing validator guard configuration.
 type Config struct {
-	NodesEndpoints      string `mapstructure:"NODES_ENDPOINTS" mandatory:"true" default:"tcp://localhost:26657"`
-	MissedBlocksLimit   int    `mapstructure:"MISSED_BLOCKS_LIMIT" mandatory:"true" default:"8"`
-	MissedBlocksWindow  int    `mapstructure:"MISSED_BLOCKS_WINDOW" mandatory:"true" default:"24"`
-	FallbackPause       int    `mapstructure:"FALLBACK_PAUSE" mandatory:"true" default:"2"`
-	NewBlockTimeout     int    `mapstructure:"NEW_BLOCK_TIMEOUT" mandatory:"true" default:"10"`
-	ValidatorAddress    string `mapstructure:"VALIDATOR_ADDRESS" mandatory:"true"`
-	SetOfflineTx        string `mapstructure:"SET_OFFLINE_TX" mandatory:"true"`
-	EnableGracePeriod   bool   `mapstructure:"ENABLE_GRACE_PERIOD" mandatory:"true" default:"true"`
-	GracePeriodDuration int    `mapstructure:"GRACE_PERIOD_DURATION" mandatory:"true" default:"15840"`
-	HttpListener        string `mapstructure:"HTTP_LISTENER" mandatory:"true"`
+	NodesEndpoints        string `mapstructure:"NODES_ENDPOINTS" mandatory:"true" default:"tcp://localhost:26657"`
+	MissedBlocksLimit     int    `mapstructure:"MISSED_BLOCKS_LIMIT" mandatory:"true" default:"8"`
+	MissedBlocksWindow    int    `mapstructure:"MISSED_BLOCKS_WINDOW" mandatory:"true" default:"24"`
+	MissedBlocksWarnings  string `mapstructure:"MISSED_BLOCKS_WARNINGS" default:"50"`
+	FallbackPause         int    `mapstructure:"FALLBACK_PAUSE" mandatory:"true" default:"2"`
+	NewBlockTimeout       int    `mapstructure:"NEW_BLOCK_TIMEOUT" mandatory:"true" default:"10"`
+	ValidatorAddress      string `mapstructure:"VALIDATOR_ADDRESS" mandatory:"true"`
+	ValidatorOperator     string `mapstructure:"VALIDATOR_OPERATOR"`
+	SetOfflineTx          string `mapstructure:"SET_OFFLINE_TX" mandatory:"true"`
+	EnableGracePeriod     bool   `mapstructure:"ENABLE_GRACE_PERIOD" mandatory:"true" default:"true"`
+	GracePeriodDuration   int    `mapstructure:"GRACE_PERIOD_DURATION" mandatory:"true" default:"15840"`
+	HttpListener          string `mapstructure:"HTTP_LISTENER" mandatory:"true"`
+	BroadcastEndpoints    string `mapstructure:"BROADCAST_ENDPOINTS"`
+	BroadcastTimeout      int    `mapstructure:"BROADCAST_TIMEOUT" default:"30"`
+	ValidatorNode         string `mapstructure:"VALIDATOR_NODE"`
+	AdminToken            string `mapstructure:"ADMIN_TOKEN"`
+	WebhookUrl            string `mapstructure:"WEBHOOK_URL"`
+	WebhookHeaders        string `mapstructure:"WEBHOOK_HEADERS"`
+	WebhookRetries        int    `mapstructure:"WEBHOOK_RETRIES" default:"3"`
+	WebhookTimeout        int    `mapstructure:"WEBHOOK_TIMEOUT" default:"10"`
+	TelegramToken         string `mapstructure:"TELEGRAM_TOKEN"`
+	TelegramChatIDs       string `mapstructure:"TELEGRAM_CHAT_IDS"`
+	TelegramApiUrl        string `mapstructure:"TELEGRAM_API_URL" default:"https://api.telegram.org"`
+	SlackWebhookUrl       string `mapstructure:"SLACK_WEBHOOK_URL"`
+	StatusPageUrl         string `mapstructure:"STATUS_PAGE_URL"`
+	AlertmanagerUrl       string `mapstructure:"ALERTMANAGER_URL"`
+	SmtpHost              string `mapstructure:"SMTP_HOST"`
+	SmtpPort              int    `mapstructure:"SMTP_PORT"`
+	SmtpSecurity          string `mapstructure:"SMTP_SECURITY" default:"starttls"`
+	SmtpUsername          string `mapstructure:"SMTP_USERNAME"`
+	SmtpPassword          string `mapstructure:"SMTP_PASSWORD"`
+	SmtpFrom              string `mapstructure:"SMTP_FROM"`
+	SmtpTo                string `mapstructure:"SMTP_TO"`
+	AlertRenotifyInterval int    `mapstructure:"ALERT_RENOTIFY_INTERVAL"`
+	AlertEscalationAfter  int    `mapstructure:"ALERT_ESCALATION_AFTER"`
+	EscalationSenders     string `mapstructure:"ESCALATION_SENDERS"`
+	DigestPeriod          string `mapstructure:"DIGEST_PERIOD"`
+	Hooks                 string `mapstructure:"HOOKS"`
+	HookTimeout           int    `mapstructure:"HOOK_TIMEOUT" default:"30"`
+	HookConcurrency       int    `mapstructure:"HOOK_CONCURRENCY" default:"2"`
+	EventHistorySize      int    `mapstructure:"EVENT_HISTORY_SIZE" default:"1000"`
+	AuditLog              string `mapstructure:"AUDIT_LOG"`
+	AuditMaxSize          int    `mapstructure:"AUDIT_MAX_SIZE" default:"10"`
+	AuditMaxFiles         int    `mapstructure:"AUDIT_MAX_FILES" default:"5"`
+	LogLevel              string `mapstructure:"LOG_LEVEL" default:"info"`
+	LogFormat             string `mapstructure:"LOG_FORMAT" default:"plain"`
 }
 
 const Subscriber = "watcher"
@@ -0,0 +1,267 @@
+package guard
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Digest periods
+const (
+	DigestDaily  = "daily"  // every day at 00:00 UTC
+	DigestWeekly = "weekly" // every Monday at 00:00 UTC
+)
+
+const maxDigestHistory = 100 // tx status changes and triggers kept per period
+
+type TxStatusChange struct {
+	Time   time.Time `json:"time"`
+	Height int64     `json:"height"`
+	Status string    `json:"status"`
+}
+
+type DigestTrigger struct {
+	Time     time.Time `json:"time"`
+	Height   int64     `json:"height"`
+	TxHash   string    `json:"tx_hash"`
+	Accepted bool      `json:"accepted"`
+	Included bool      `json:"included"`
+}
+
+// DigestReport is summary of guard work for period
+type DigestReport struct {
+	From              time.Time          `json:"from"`
+	To                time.Time          `json:"to"`
+	FromHeight        int64              `json:"from_height"`
+	ToHeight          int64              `json:"to_height"`
+	Signed            int                `json:"signed"`
+	Missed            int                `json:"missed"`
+	Unknown           int                `json:"unknown"` // validator was offline
+	Uptime            float64            `json:"uptime"`  // percent of signed blocks of known
+	LongestMissStreak int                `json:"longest_miss_streak"`
+	Watchers          map[string]float64 `json:"watchers"` // percent of time watcher was watching
+	TxHistory         []TxStatusChange   `json:"tx_history"`
+	Triggers          []DigestTrigger    `json:"triggers"`
+}
+
+type watcherUptime struct {
+	watching bool
+	since    time.Time     // last state change or period start
+	first    time.Time     // first report in period
+	watched  time.Duration // closed watching intervals
+}
+
+// digestStats accumulates data for digest of current period
+type digestStats struct {
+	mu         sync.Mutex
+	from       time.Time
+	fromHeight int64
+	signed     int
+	missed     int
+	unknown    int
+	streak     int // current miss streak
+	longest    int
+	watchers   map[string]*watcherUptime
+	txStatus   string
+	txHistory  []TxStatusChange
+	triggers   []DigestTrigger
+}
+
+func newDigestStats(now time.Time) *digestStats {
+	return &digestStats{from: now, watchers: make(map[string]*watcherUptime)}
+}
+
+func (ds *digestStats) addBlock(height int64, known bool, signed bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.fromHeight == 0 {
+		ds.fromHeight = height
+	}
+	switch {
+	case !known:
+		ds.unknown++
+		ds.streak = 0
+	case signed:
+		ds.signed++
+		ds.streak = 0
+	default:
+		ds.missed++
+		ds.streak++
+		if ds.streak > ds.longest {
+			ds.longest = ds.streak
+		}
+	}
+}
+
+func (ds *digestStats) setWatcher(node string, watching bool, now time.Time) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	wu, ok := ds.watchers[node]
+	if !ok {
+		ds.watchers[node] = &watcherUptime{watching: watching, since: now, first: now}
+		return
+	}
+	if wu.watching == watching {
+		return
+	}
+	if wu.watching {
+		wu.watched += now.Sub(wu.since)
+	}
+	wu.watching = watching
+	wu.since = now
+}
+
+func (ds *digestStats) setTxStatus(status string, height int64, now time.Time) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if status == ds.txStatus {
+		return
+	}
+	ds.txStatus = status
+	if len(ds.txHistory) < maxDigestHistory {
+		ds.txHistory = append(ds.txHistory, TxStatusChange{Time: now, Height: height, Status: status})
+	}
+}
+
+func (ds *digestStats) addTrigger(trigger DigestTrigger) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if len(ds.triggers) < maxDigestHistory {
+		ds.triggers = append(ds.triggers, trigger)
+	}
+}
+
+// report returns digest until now, with rotate statistics start new period
+func (ds *digestStats) report(now time.Time, height int64, rotate bool) DigestReport {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	r := DigestReport{
+		From:              ds.from,
+		To:                now,
+		FromHeight:        ds.fromHeight,
+		ToHeight:          height,
+		Signed:            ds.signed,
+		Missed:            ds.missed,
+		Unknown:           ds.unknown,
+		LongestMissStreak: ds.longest,
+		Watchers:          make(map[string]float64),
+		TxHistory:         append([]TxStatusChange{}, ds.txHistory...),
+		Triggers:          append([]DigestTrigger{}, ds.triggers...),
+	}
+	if ds.signed+ds.missed > 0 {
+		r.Uptime = percent(float64(ds.signed), float64(ds.signed+ds.missed))
+	}
+	for node, wu := range ds.watchers {
+		watched := wu.watched
+		if wu.watching {
+			watched += now.Sub(wu.since)
+		}
+		if total := now.Sub(wu.first); total > 0 {
+			r.Watchers[node] = percent(float64(watched), float64(total))
+		}
+	}
+	if rotate {
+		ds.from, ds.fromHeight = now, 0
+		ds.signed, ds.missed, ds.unknown, ds.longest = 0, 0, 0, ds.streak
+		ds.txHistory, ds.triggers = nil, nil
+		for _, wu := range ds.watchers {
+			wu.since, wu.first, wu.watched = now, now, 0
+		}
+	}
+	return r
+}
+
+// percent rounded to 0.01
+func percent(part, total float64) float64 {
+	return float64(int64(part/total*10000+0.5)) / 100
+}
+
+// NextDigest returns time of next digest after now
+func NextDigest(now time.Time, period string) (time.Time, error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case DigestDaily:
+		return day.AddDate(0, 0, 1), nil
+	case DigestWeekly:
+		days := (8 - int(day.Weekday())) % 7
+		if days == 0 {
+			days = 7
+		}
+		return day.AddDate(0, 0, days), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown digest period '%s', expected %s or %s", period, DigestDaily, DigestWeekly)
+}
+
+// Digest returns digest of current period
+func (sm *GuardStateMachine) Digest() DigestReport {
+	return sm.digest.report(time.Now(), sm.height(), false)
+}
+
+// height is current height for readers outside of event processing
+func (sm *GuardStateMachine) height() int64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.currentHeight
+}
+
+// LastDigest returns digest of previous period (empty if it is not sent yet)
+func (sm *GuardStateMachine) LastDigest() DigestReport {
+	sm.digestMu.Lock()
+	defer sm.digestMu.Unlock()
+	return sm.lastDigest
+}
+
+// RunDigest sends digest to notifier every period until guard is stopped
+func (sm *GuardStateMachine) RunDigest(period string) {
+	next, err := NextDigest(time.Now(), period)
+	if err != nil {
+		sm.logger.Error("digest is disabled", "err", err.Error())
+		return
+	}
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for range tick.C {
+		if !sm.isRunning {
+			return
+		}
+		now := time.Now()
+		if now.Before(next) {
+			continue
+		}
+		next, _ = NextDigest(now, period)
+		sm.sendDigest(sm.digest.report(now, sm.height(), true), period)
+	}
+}
+
+func (sm *GuardStateMachine) sendDigest(r DigestReport, period string) {
+	sm.digestMu.Lock()
+	sm.lastDigest = r
+	sm.digestMu.Unlock()
+	nodes := make([]string, 0, len(r.Watchers))
+	for node := range r.Watchers {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	watchers := ""
+	for _, node := range nodes {
+		watchers += fmt.Sprintf("%s %.2f%%; ", node, r.Watchers[node])
+	}
+	sm.logger.Info("digest", "period", period, "signed", r.Signed, "missed", r.Missed, "uptime", fmt.Sprintf("%.2f%%", r.Uptime))
+	// notification reads state and height like event processing does
+	sm.stateMu.Lock()
+	defer sm.stateMu.Unlock()
+	sm.notify(NotifyDigest, SeverityInfo, fmt.Sprintf("%s digest from %s to %s", period, r.From.UTC().Format(time.RFC3339), r.To.UTC().Format(time.RFC3339)),
+		map[string]interface{}{
+			"blocks":              fmt.Sprintf("%d..%d", r.FromHeight, r.ToHeight),
+			"signed":              r.Signed,
+			"missed":              r.Missed,
+			"unknown":             r.Unknown,
+			"uptime":              fmt.Sprintf("%.2f%%", r.Uptime),
+			"longest_miss_streak": r.LongestMissStreak,
+			"watchers":            watchers,
+			"tx_history":          r.TxHistory,
+			"triggers":            r.Triggers,
+		})
+}
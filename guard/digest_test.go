@@ -0,0 +1,57 @@
+package guard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestStats(t *testing.T) {
+	start := time.Date(2022, 11, 21, 0, 0, 0, 0, time.UTC)
+	ds := newDigestStats(start)
+	ds.setWatcher("a", true, start)
+	ds.setWatcher("b", false, start)
+	ds.setTxStatus("valid", 1, start)
+	for h, sign := range []string{"+", "-", "-", "+", "?", "-", "-", "-", "+"} {
+		ds.addBlock(int64(h+1), sign != "?", sign == "+")
+	}
+	ds.setWatcher("a", false, start.Add(time.Hour))
+	ds.setWatcher("b", true, start.Add(3*time.Hour))
+	ds.setTxStatus("valid", 5, start.Add(time.Hour))
+	ds.setTxStatus("invalid: sequence_used", 7, start.Add(2*time.Hour))
+	ds.addTrigger(DigestTrigger{Height: 9, TxHash: "AB", Accepted: true, Included: true})
+
+	r := ds.report(start.Add(4*time.Hour), 9, true)
+	require.Equal(t, int64(1), r.FromHeight)
+	require.Equal(t, 3, r.Signed)
+	require.Equal(t, 5, r.Missed)
+	require.Equal(t, 1, r.Unknown)
+	require.Equal(t, 37.5, r.Uptime)
+	require.Equal(t, 3, r.LongestMissStreak)
+	require.Equal(t, map[string]float64{"a": 25, "b": 25}, r.Watchers)
+	require.Len(t, r.TxHistory, 2)
+	require.Len(t, r.Triggers, 1)
+
+	// new period
+	r = ds.report(start.Add(5*time.Hour), 9, false)
+	require.Equal(t, 0, r.Signed)
+	require.Equal(t, map[string]float64{"a": 0, "b": 100}, r.Watchers)
+	require.Empty(t, r.TxHistory)
+}
+
+func TestNextDigest(t *testing.T) {
+	// Wednesday
+	now := time.Date(2022, 11, 23, 15, 30, 0, 0, time.UTC)
+	next, err := NextDigest(now, DigestDaily)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2022, 11, 24, 0, 0, 0, 0, time.UTC), next)
+	next, err = NextDigest(now, DigestWeekly)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC), next)
+	// Monday midnight: next week
+	next, _ = NextDigest(time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC), DigestWeekly)
+	require.Equal(t, time.Date(2022, 12, 5, 0, 0, 0, 0, time.UTC), next)
+	_, err = NextDigest(now, "hourly")
+	require.Error(t, err)
+}
@@ -5,6 +5,17 @@ type eventWatcherState struct {
 	state WatcherState
 }
 
+// connection or query error of watcher
+type eventWatcherError struct {
+	node string
+	err  string
+}
+
+// eventWatcherOk is sent when watcher succeeds after reported error
+type eventWatcherOk struct {
+	node string
+}
+
 type eventTxValidity struct {
 	node  string
 	valid bool
@@ -17,3 +28,12 @@ type eventValidatorState struct {
 }
 
 type eventValidatorSkipSign struct{}
+
+type eventTxDiagnosis struct {
+	node    string
+	reason  TxInvalidReason
+	details string
+}
+
+// set_online transaction is in block
+type eventSetOnline struct{}
@@ -3,7 +3,9 @@ package guard
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tmlog "github.com/tendermint/tendermint/libs/log"
@@ -13,6 +15,7 @@ import (
 
 type GuardStateMachine struct {
 	signWindow        []bool
+	signKnown         []bool // sign of block is known: validator was online
 	currentHeight     int64
 	lastHeightUpdate  time.Time
 	config            Config
@@ -21,8 +24,13 @@ type GuardStateMachine struct {
 	eventReadTimeout  time.Duration
 	watchersState     map[string]WatcherState
 	isTxValid         map[string]TxState
+	txDiagnosis       map[string]eventTxDiagnosis
 	isValidatorOnline bool
 	isSkipSign        bool
+	graceUntil        int64 // set_offline is not sent until this height
+	warnings          []int // warning thresholds in percents of MissedBlocksLimit
+	missedBlocks      int   // missed blocks in window
+	warningLevel      int   // highest reached warning threshold, 0 - none
 
 	logger tmlog.Logger
 
@@ -30,13 +38,34 @@ type GuardStateMachine struct {
 
 	setOfflineCallback setOfflineFunc
 
-	mu sync.Mutex
+	notifier   Notifier
+	openAlerts map[string]Notification // fired and not resolved alerts by key
+	txAlertKey string                  // key of open alert about invalid transaction
+	alertsMu   sync.Mutex
+
+	digest     *digestStats
+	lastDigest DigestReport
+	digestMu   sync.Mutex
+
+	mu      sync.Mutex
+	stateMu sync.RWMutex // event processing in ProcessEvent, readers of state use RLock
+
+	lastSigned      int64 // last height signed by validator
+	setOfflineCount int   // count of set_offline broadcasts
+	loopAt          int64 // unix nanoseconds of last iteration of Start loop, atomic
+
+	history   *history
+	audit     *AuditLog
+	lastEvent string // name of event in processing, for audit
 }
 
 // minimal interface for Watcher
 type Guarder interface {
 	ReportWatcher(id string, state WatcherState)
+	ReportWatcherError(id string, err error)
+	ReportWatcherOk(id string)
 	ReportTxValidity(id string, valid bool)
+	ReportTxDiagnosis(id string, reason TxInvalidReason, details string)
 	ReportValidatorOnline(id string, height int64, online bool)
 	SetSign(height int64, signed bool)
 }
@@ -45,32 +74,64 @@ type setOfflineFunc func()
 
 func NewGuardState(logger tmlog.Logger, config Config, callback setOfflineFunc) *GuardStateMachine {
 	var signWindow = make([]bool, config.MissedBlocksWindow)
+	// configuration is validated at start (see ParseWarnings)
+	warnings, _ := ParseWarnings(config.MissedBlocksWarnings)
 	sm := &GuardStateMachine{
 		eventChannel:       make(chan interface{}, 1000),
 		eventReadTimeout:   time.Second,
 		watchersState:      make(map[string]WatcherState),
 		isTxValid:          make(map[string]TxState),
+		txDiagnosis:        make(map[string]eventTxDiagnosis),
+		openAlerts:         make(map[string]Notification),
+		digest:             newDigestStats(time.Now()),
 		isValidatorOnline:  false,
 		state:              StateStarting,
 		logger:             logger,
 		config:             config,
 		signWindow:         signWindow,
+		signKnown:          make([]bool, config.MissedBlocksWindow),
+		warnings:           warnings,
 		setOfflineCallback: callback,
 		isRunning:          false,
 		lastHeightUpdate:   time.Now(),
+		history:            newHistory(config.EventHistorySize),
 	}
 	sm.ResetWindow()
 	return sm
 }
 
+// ProcessEvent changes state of guard by event. Set_offline is broadcasted after state lock is released:
+// broadcast takes up to BROADCAST_TIMEOUT and readers of state must not wait for it.
 func (sm *GuardStateMachine) ProcessEvent(ev interface{}) {
+	sm.stateMu.Lock()
+	setOffline := sm.processEvent(ev)
+	sm.stateMu.Unlock()
+	if setOffline {
+		sm.setOfflineCallback()
+	}
+}
+
+// processEvent returns true if set_offline must be sent
+func (sm *GuardStateMachine) processEvent(ev interface{}) (setOffline bool) {
+	sm.lastEvent = eventName(ev)
+	sm.logger.Debug("process event", "event", sm.lastEvent, "state", StateName(sm.state))
+	sm.recordEvent(ev)
 	txValid, ok := ev.(eventTxValidity)
 	if ok {
 		if txValid.valid {
 			sm.isTxValid[txValid.node] = TxValid
+			delete(sm.txDiagnosis, txValid.node)
 		} else {
 			sm.isTxValid[txValid.node] = TxInvalid
 		}
+		if sm.summaryTxValidity() != TxInvalid {
+			sm.resolveTxDiagnosis()
+		}
+	}
+	txDiagnosis, ok := ev.(eventTxDiagnosis)
+	if ok {
+		sm.txDiagnosis[txDiagnosis.node] = txDiagnosis
+		sm.notifyTxDiagnosis()
 	}
 	// TODO: check correctness of summaryValidatorOnline for multiple watchers
 	// when watchers online-offline, skip blocks etc.
@@ -82,114 +143,126 @@ func (sm *GuardStateMachine) ProcessEvent(ev interface{}) {
 	}
 	watcherState, ok := ev.(eventWatcherState)
 	if ok {
+		if sm.watchersState[watcherState.node] == WatcherWatching && watcherState.state == WatcherConnecting {
+			sm.fire(NotifyWatcherDown+":"+watcherState.node, NotifyWatcherDown, SeverityWarning,
+				fmt.Sprintf("watcher %s is disconnected from node", watcherState.node), map[string]interface{}{"node": watcherState.node})
+		}
+		if watcherState.state == WatcherWatching {
+			sm.resolve(NotifyWatcherDown + ":" + watcherState.node)
+			sm.resolve(NotifyWatcherError + ":" + watcherState.node)
+		}
 		sm.watchersState[watcherState.node] = watcherState.state
+		sm.digest.setWatcher(watcherState.node, watcherState.state == WatcherWatching, time.Now())
+	}
+	watcherError, ok := ev.(eventWatcherError)
+	if ok {
+		sm.fire(NotifyWatcherError+":"+watcherError.node, NotifyWatcherError, SeverityWarning,
+			fmt.Sprintf("watcher %s: %s", watcherError.node, watcherError.err), map[string]interface{}{"node": watcherError.node})
+	}
+	watcherOk, ok := ev.(eventWatcherOk)
+	if ok {
+		sm.resolve(NotifyWatcherError + ":" + watcherOk.node)
 	}
 	_, ok = ev.(eventValidatorSkipSign)
 	if ok {
 		sm.isSkipSign = true
 	}
+	_, ok = ev.(eventSetOnline)
+	if ok {
+		sm.processSetOnline()
+	}
+	txStatus, txReason, _ := sm.txStatus()
+	if txReason > "" {
+		txStatus += ": " + txReason
+	}
+	sm.digest.setTxStatus(txStatus, sm.currentHeight, time.Now())
 	// process event, change state
 	switch sm.state {
 	case StateStarting:
 		{
 			if sm.summaryWatcherState() == WatcherConnecting {
-				sm.state = StateConnecting
-				sm.logger.Debug("guard state transition StateStarting->StateConnecting")
+				sm.transition(StateConnecting)
 				break
 			}
 			if sm.summaryWatcherState() == WatcherWatching {
 				sm.ResetWindow()
 				sm.isSkipSign = false
-				sm.state = StateWatching
+				next := StateWatching
 				if !sm.summaryValidatorOnline() && sm.summaryTxValidity() == TxValid {
-					sm.logger.Debug("guard state transition StateStarting->StateValidatorIsOffline")
-					sm.state = StateValidatorIsOffline
+					next = StateValidatorIsOffline
 				}
 				if sm.summaryTxValidity() == TxInvalid {
-					sm.logger.Debug("guard state transition StateStarting->StateWatchingWithoutTx")
-					sm.state = StateWatchingWithoutTx
+					next = StateWatchingWithoutTx
 				}
+				sm.transition(next)
 				break
 			}
 		}
 	case StateConnecting:
 		{
 			if sm.summaryWatcherState() == WatcherWatching && sm.summaryValidatorOnline() && sm.summaryTxValidity() == TxValid {
-				sm.logger.Debug("guard state transition StateConnecting->StateWatching")
-				sm.state = StateWatching
+				sm.transition(StateWatching)
 				break
 			}
 			if sm.summaryWatcherState() == WatcherWatching && !sm.summaryValidatorOnline() && sm.summaryTxValidity() == TxValid {
-				sm.logger.Debug("guard state transition StateConnecting->StateValidatorIsOffline")
-				sm.state = StateValidatorIsOffline
+				sm.transition(StateValidatorIsOffline)
 				break
 			}
 			if sm.summaryWatcherState() == WatcherWatching && sm.summaryTxValidity() != TxValid {
-				sm.logger.Debug("guard state transition StateConnecting->StateWatchingWithoutTx")
-				sm.state = StateWatchingWithoutTx
+				sm.transition(StateWatchingWithoutTx)
 				break
 			}
 		}
 	case StateWatching:
 		{
 			if sm.summaryWatcherState() == WatcherConnecting {
-				sm.logger.Debug("guard state transition StateWatching->StateConnecting")
-				sm.state = StateConnecting
+				sm.transition(StateConnecting)
 				break
 			}
 			if sm.summaryTxValidity() != TxValid {
-				sm.logger.Debug("guard state transition StateWatching->StateWatchingWithoutTx")
-				sm.state = StateWatchingWithoutTx
+				sm.transition(StateWatchingWithoutTx)
 				break
 			}
 			if sm.summaryTxValidity() == TxValid && !sm.summaryValidatorOnline() {
-				sm.logger.Debug("guard state transition StateWatching->StateValidatorIsOffline")
-				sm.state = StateValidatorIsOffline
+				sm.transition(StateValidatorIsOffline)
 				break
 			}
 			if sm.isSkipSign {
-				sm.logger.Info("guard: send set_offline")
-				sm.setOfflineCallback()
-				sm.logger.Debug("guard state transition StateWatching->StateStarting")
-				sm.state = StateStarting
+				sm.logger.Info("send set_offline", "height", sm.currentHeight, "state", StateName(sm.state))
+				setOffline = true
+				sm.transition(StateStarting)
 				break
 			}
 		}
 	case StateValidatorIsOffline:
 		{
 			if sm.summaryWatcherState() == WatcherConnecting {
-				sm.logger.Debug("guard state transition StateValidatorIsOffline->StateConnecting")
-				sm.state = StateConnecting
+				sm.transition(StateConnecting)
 				break
 			}
 			if sm.summaryTxValidity() == TxValid && sm.summaryValidatorOnline() {
-				sm.logger.Debug("guard state transition StateValidatorIsOffline->StateWatching")
-				sm.state = StateWatching
+				sm.transition(StateWatching)
 				break
 			}
 		}
 	case StateWatchingWithoutTx:
 		{
 			if sm.summaryWatcherState() == WatcherConnecting {
-				sm.logger.Debug("guard state transition StateWatchingWithoutTx->StateConnecting")
-				sm.state = StateConnecting
+				sm.transition(StateConnecting)
 				break
 			}
 			if sm.summaryTxValidity() == TxValid && sm.summaryValidatorOnline() {
-				sm.logger.Debug("guard state transition StateWatchingWithoutTx->StateWatching")
-				sm.state = StateWatching
+				sm.transition(StateWatching)
 				break
 			}
 			if sm.summaryTxValidity() == TxValid && !sm.summaryValidatorOnline() {
-				sm.logger.Debug("guard state transition StateWatchingWithoutTx->StateValidatorIsOffline")
-				sm.state = StateValidatorIsOffline
+				sm.transition(StateValidatorIsOffline)
 				break
 			}
-			if sm.summaryValidatorOnline() && sm.summaryTxValidity() == TxInvalid {
-				sm.logger.Error("Validator is online, but transaction is invalid! You can't protect validator from slash")
-			}
+			sm.notifyUnprotected()
 		}
 	}
+	return setOffline
 }
 
 func (sm *GuardStateMachine) Start() {
@@ -197,6 +270,7 @@ func (sm *GuardStateMachine) Start() {
 	sm.state = StateStarting
 	tick := time.NewTicker(sm.eventReadTimeout)
 	for sm.isRunning {
+		atomic.StoreInt64(&sm.loopAt, time.Now().UnixNano())
 		select {
 		case ev := <-sm.eventChannel:
 			{
@@ -218,6 +292,7 @@ func (sm *GuardStateMachine) Stop() {
 func (sm *GuardStateMachine) ResetWindow() {
 	for i := range sm.signWindow {
 		sm.signWindow[i] = true
+		sm.signKnown[i] = false
 	}
 }
 
@@ -225,10 +300,21 @@ func (sm *GuardStateMachine) SetSign(height int64, signed bool) {
 	if !sm.isRunning {
 		return
 	}
+	if sm.setSign(height, signed) {
+		sm.eventChannel <- eventValidatorSkipSign{}
+	}
+}
+
+// setSign updates sign window, it returns true if missed blocks limit is reached.
+// Validator online and state of guard are read by window and notifications, so event processing waits for it,
+// while events read currentHeight without sm.mu.
+func (sm *GuardStateMachine) setSign(height int64, signed bool) bool {
+	sm.stateMu.RLock()
+	defer sm.stateMu.RUnlock()
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	if height <= sm.currentHeight {
-		return
+		return false
 	}
 	if sm.currentHeight == 0 {
 		sm.currentHeight = height
@@ -239,10 +325,18 @@ func (sm *GuardStateMachine) SetSign(height int64, signed bool) {
 	}
 	sm.currentHeight = height
 	sm.lastHeightUpdate = time.Now()
+	idx := int(sm.currentHeight) % sm.config.MissedBlocksWindow
+	sm.digest.addBlock(height, sm.summaryValidatorOnline(), signed)
+	sm.record(HistorySign, "guard", height, map[string]interface{}{"signed": signed, "validator_online": sm.summaryValidatorOnline()})
+	if signed {
+		sm.lastSigned = height
+	}
 	if sm.summaryValidatorOnline() {
-		sm.signWindow[int(sm.currentHeight)%sm.config.MissedBlocksWindow] = signed
+		sm.signWindow[idx] = signed
+		sm.signKnown[idx] = true
 	} else {
-		sm.signWindow[int(sm.currentHeight)%sm.config.MissedBlocksWindow] = true
+		sm.signWindow[idx] = true
+		sm.signKnown[idx] = false
 	}
 	notSignedCount := 0
 	for _, signed := range sm.signWindow {
@@ -251,11 +345,17 @@ func (sm *GuardStateMachine) SetSign(height int64, signed bool) {
 		}
 	}
 
-	sm.logger.Debug(fmt.Sprintf("missed blocks in window = %d", notSignedCount))
+	sm.logger.Debug("missed blocks in window", "height", height, "missed", notSignedCount)
+	sm.notifyMissedBlocks(notSignedCount)
 
 	if notSignedCount >= sm.config.MissedBlocksLimit {
-		sm.eventChannel <- eventValidatorSkipSign{}
+		if height <= sm.graceUntil {
+			sm.logger.Info("missed blocks limit is reached in grace period, set_offline is not sent", "height", height, "grace_until", sm.graceUntil)
+			return false
+		}
+		return true
 	}
+	return false
 }
 
 func (sm *GuardStateMachine) ReportWatcher(id string, state WatcherState) {
@@ -265,6 +365,20 @@ func (sm *GuardStateMachine) ReportWatcher(id string, state WatcherState) {
 	sm.eventChannel <- eventWatcherState{node: id, state: state}
 }
 
+func (sm *GuardStateMachine) ReportWatcherError(id string, err error) {
+	if !sm.isRunning {
+		return
+	}
+	sm.eventChannel <- eventWatcherError{node: id, err: err.Error()}
+}
+
+func (sm *GuardStateMachine) ReportWatcherOk(id string) {
+	if !sm.isRunning {
+		return
+	}
+	sm.eventChannel <- eventWatcherOk{node: id}
+}
+
 func (sm *GuardStateMachine) ReportTxValidity(id string, valid bool) {
 	if !sm.isRunning {
 		return
@@ -272,6 +386,13 @@ func (sm *GuardStateMachine) ReportTxValidity(id string, valid bool) {
 	sm.eventChannel <- eventTxValidity{node: id, valid: valid}
 }
 
+func (sm *GuardStateMachine) ReportTxDiagnosis(id string, reason TxInvalidReason, details string) {
+	if !sm.isRunning {
+		return
+	}
+	sm.eventChannel <- eventTxDiagnosis{node: id, reason: reason, details: details}
+}
+
 func (sm *GuardStateMachine) ReportValidatorOnline(id string, height int64, online bool) {
 	if !sm.isRunning {
 		return
@@ -303,50 +424,94 @@ func (sm *GuardStateMachine) summaryTxValidity() TxState {
 	return TxValid
 }
 
+// summaryTxDiagnosis returns explanation of invalid transaction from any watcher
+// which reported it; nodes are checked in sorted order to keep result stable
+func (sm *GuardStateMachine) summaryTxDiagnosis() (TxInvalidReason, string) {
+	nodes := make([]string, 0, len(sm.txDiagnosis))
+	for node := range sm.txDiagnosis {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		if sm.isTxValid[node] == TxInvalid {
+			return sm.txDiagnosis[node].reason, sm.txDiagnosis[node].details
+		}
+	}
+	return TxReasonUnknown, ""
+}
+
 func (sm *GuardStateMachine) summaryValidatorOnline() bool {
 	return sm.isValidatorOnline
 }
 
-// GetJsonStatus return current state of guard in json
-func (sm *GuardStateMachine) GetJsonStatus() []byte {
-	critical := ""
-	if sm.summaryWatcherState() == WatcherConnecting {
-		critical = "watchers are disconnected from nodes"
-	}
-	if sm.summaryValidatorOnline() && sm.summaryTxValidity() == TxInvalid {
-		critical = "validator is online and transaction is invalid"
+// StatusVersion is incremented on incompatible changes of GuardStatus json
+const StatusVersion = 1
+
+// GuardStatus is current state of guard, it is shown as json on report page
+type GuardStatus struct {
+	Version                  int          `json:"version"`
+	State                    string       `json:"state"`
+	Critical                 string       `json:"critical"`
+	ValidatorOnline          bool         `json:"validator_online"`
+	TransactionStatus        string       `json:"transaction_status"`
+	TransactionInvalidReason string       `json:"transaction_invalid_reason"`
+	TransactionError         string       `json:"transaction_error"`
+	WatchersCount            int          `json:"watchers_count"`
+	WatchersWatching         int          `json:"watchers_watching"`
+	CurrentHeight            int64        `json:"current_height"`
+	GracePeriodUntil         int64        `json:"grace_period_until"`
+	MissedBlocks             int          `json:"missed_blocks"`
+	Degraded                 bool         `json:"degraded"`
+	WarningThreshold         int          `json:"warning_threshold"`
+	Window                   WindowStatus `json:"window"`
+}
+
+// Status returns current state of guard
+func (sm *GuardStateMachine) Status() GuardStatus {
+	window := sm.SignWindow()
+	sm.mu.Lock()
+	status := GuardStatus{
+		Version:          StatusVersion,
+		CurrentHeight:    window.Height,
+		GracePeriodUntil: sm.graceUntil,
+		MissedBlocks:     sm.missedBlocks,
+		Degraded:         sm.warningLevel > 0,
+		WarningThreshold: sm.warningLevel,
+		Window:           window,
 	}
-	if critical == "" && time.Now().Sub(sm.lastHeightUpdate).Seconds() > float64(sm.config.NewBlockTimeout) {
-		critical = fmt.Sprintf("last block received more than %d seconds ago", sm.config.NewBlockTimeout)
+	sm.mu.Unlock()
+	sm.stateMu.RLock()
+	defer sm.stateMu.RUnlock()
+	status.State = StateName(sm.state)
+	status.ValidatorOnline = sm.summaryValidatorOnline()
+	if problems := sm.problems(time.Now()); len(problems) > 0 {
+		status.Critical = problems[0]
 	}
-	watchers_count := 0
-	watchers_watching := 0
 	for _, ws := range sm.watchersState {
-		watchers_count++
+		status.WatchersCount++
 		if ws == WatcherWatching {
-			watchers_watching++
+			status.WatchersWatching++
 		}
 	}
-	tx_validity := ""
+	status.TransactionStatus, status.TransactionInvalidReason, status.TransactionError = sm.txStatus()
+	return status
+}
+
+// txStatus returns transaction_status, transaction_invalid_reason and transaction_error of status
+func (sm *GuardStateMachine) txStatus() (string, string, string) {
 	switch sm.summaryTxValidity() {
-	case TxUnknown:
-		tx_validity = "unknown"
 	case TxInvalid:
-		tx_validity = "invalid"
+		reason, details := sm.summaryTxDiagnosis()
+		return "invalid", TxReasonName(reason), details
 	case TxValid:
-		tx_validity = "valid"
-	}
-
-	bz, err := json.Marshal(
-		map[string]interface{}{
-			"validator_online":   sm.summaryValidatorOnline(),
-			"transaction_status": tx_validity,
-			"critical":           critical,
-			"watchers_count":     watchers_count,
-			"watchers_watching":  watchers_watching,
-			"current_height":     sm.currentHeight,
-		},
-	)
+		return "valid", "", ""
+	}
+	return "unknown", "", ""
+}
+
+// GetJsonStatus return current state of guard in json
+func (sm *GuardStateMachine) GetJsonStatus() []byte {
+	bz, err := json.Marshal(sm.Status())
 	if err != nil {
 		return []byte("{}")
 	}
@@ -1,6 +1,8 @@
 package guard
 
 import (
+	"bytes"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -69,6 +71,22 @@ func TestGuardStateTxTrigger(t *testing.T) {
 	require.Equal(t, StateStarting, gsm.state)
 }
 
+func TestGuardSetOfflineOutsideLock(t *testing.T) {
+	var status GuardStatus
+	var gsm *GuardStateMachine
+	gsm = NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 8, MissedBlocksWindow: 24}, func() {
+		// readers of state are not blocked by broadcast
+		status = gsm.Status()
+		gsm.NotifySetOffline(BroadcastReport{})
+	})
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventValidatorState{"a", 1, true})
+	gsm.ProcessEvent(eventTxValidity{"a", true})
+	gsm.ProcessEvent(eventValidatorSkipSign{})
+	require.Equal(t, "starting", status.State)
+	require.Equal(t, 1, gsm.setOfflineCount)
+}
+
 func TestGuardRun(t *testing.T) {
 	var isOfflineTriggered = false
 	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 8, MissedBlocksWindow: 24}, func() {
@@ -128,3 +146,178 @@ func TestGuardRun(t *testing.T) {
 	require.Equal(t, StateValidatorIsOffline, gsm.state)
 	gsm.Stop()
 }
+
+func TestGuardGracePeriod(t *testing.T) {
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{
+		MissedBlocksLimit:   2,
+		MissedBlocksWindow:  4,
+		EnableGracePeriod:   true,
+		GracePeriodDuration: 10,
+	}, nil)
+	gsm.isRunning = true
+	gsm.isValidatorOnline = true
+	gsm.currentHeight = 100
+	gsm.ProcessEvent(eventSetOnline{})
+	require.Equal(t, int64(110), gsm.graceUntil)
+	// missed blocks in grace period don't trigger set_offline
+	for h := int64(101); h <= 110; h++ {
+		gsm.SetSign(h, false)
+	}
+	require.Len(t, gsm.eventChannel, 0)
+	gsm.SetSign(111, false)
+	require.Len(t, gsm.eventChannel, 1)
+}
+
+type recordNotifier struct {
+	notifications []Notification
+}
+
+func (rn *recordNotifier) Notify(n Notification) {
+	rn.notifications = append(rn.notifications, n)
+}
+
+func TestGuardNotifications(t *testing.T) {
+	rn := &recordNotifier{}
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{ValidatorAddress: "AA"}, nil)
+	gsm.SetNotifier(rn)
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventTxValidity{"a", false})
+	gsm.ProcessEvent(eventTxDiagnosis{"a", TxReasonSequenceUsed, "sequence 1 already used"})
+	// alert is fired again, duplicates are removed by alert manager
+	gsm.ProcessEvent(eventTxValidity{"a", false})
+	gsm.ProcessEvent(eventTxDiagnosis{"a", TxReasonSequenceUsed, "sequence 1 already used"})
+	gsm.ProcessEvent(eventWatcherError{"a", "connection refused"})
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherConnecting})
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventTxValidity{"a", true})
+
+	var events []string
+	for _, n := range rn.notifications {
+		require.Equal(t, "AA", n.Validator)
+		event := n.Type
+		if n.Key > "" {
+			event = n.Key
+		}
+		if n.Resolved {
+			event = "resolved " + event
+		}
+		events = append(events, event)
+	}
+	require.Equal(t, []string{
+		NotifyStateChanged,
+		NotifyWatchingWithoutTx,
+		"tx_invalid:sequence_used",
+		"tx_invalid:sequence_used",
+		"watcher_error:a",
+		"watcher_down:a",
+		"resolved " + NotifyWatchingWithoutTx,
+		NotifyWatchersDown,
+		"resolved watcher_down:a",
+		"resolved watcher_error:a",
+		"resolved " + NotifyWatchersDown,
+		NotifyWatchingWithoutTx,
+		"resolved tx_invalid:sequence_used",
+		"resolved " + NotifyWatchingWithoutTx,
+		AlertValidatorOffline,
+	}, events)
+	require.Equal(t, "sequence_used", rn.notifications[2].Details["reason"])
+	require.Equal(t, "connecting", rn.notifications[7].State)
+}
+
+func TestGuardUnprotectedOnce(t *testing.T) {
+	var log bytes.Buffer
+	rn := &recordNotifier{}
+	gsm := NewGuardState(tmlog.NewTMLogger(&log), Config{}, nil)
+	gsm.SetNotifier(rn)
+	gsm.ProcessEvent(eventValidatorState{"a", 1, true})
+	gsm.ProcessEvent(eventTxValidity{"a", false})
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	require.Equal(t, StateWatchingWithoutTx, gsm.state)
+	for i := 0; i < 5; i++ {
+		gsm.ProcessEvent(eventTxValidity{"a", false})
+	}
+	require.Equal(t, 1, strings.Count(log.String(), "transaction is invalid! You can't protect validator"))
+	unprotected := 0
+	for _, n := range rn.notifications {
+		if n.Type == NotifyUnprotected {
+			unprotected++
+		}
+	}
+	require.Equal(t, 1, unprotected)
+}
+
+func TestGuardMissedBlocksWarnings(t *testing.T) {
+	rn := &recordNotifier{}
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{
+		MissedBlocksLimit:    8,
+		MissedBlocksWindow:   24,
+		MissedBlocksWarnings: "75, 50%",
+	}, nil)
+	gsm.SetNotifier(rn)
+	gsm.isRunning = true
+	gsm.isValidatorOnline = true
+	for h := int64(1); h <= 4; h++ {
+		gsm.SetSign(h, false)
+	}
+	require.Equal(t, true, gsm.Status().Degraded)
+	require.Equal(t, 50, gsm.Status().WarningThreshold)
+	gsm.SetSign(5, false)
+	gsm.SetSign(6, false)
+	require.Equal(t, 75, gsm.Status().WarningThreshold)
+	require.Len(t, gsm.eventChannel, 0)
+
+	gsm.ResetWindow()
+	gsm.SetSign(7, true)
+	require.Equal(t, false, gsm.Status().Degraded)
+	last := rn.notifications[len(rn.notifications)-2:]
+	require.True(t, last[0].Resolved)
+	require.Equal(t, "missed_blocks:50", last[0].Key)
+	require.Equal(t, "missed_blocks:75", last[1].Key)
+
+	_, err := ParseWarnings("50,100")
+	require.Error(t, err)
+}
+
+func TestGuardStatus(t *testing.T) {
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 3, MissedBlocksWindow: 5}, nil)
+	gsm.isRunning = true
+	gsm.isValidatorOnline = true
+	gsm.SetSign(1, true)
+	gsm.SetSign(2, false)
+	gsm.SetSign(3, true)
+	gsm.SetSign(4, false)
+	gsm.SetSign(5, false)
+
+	status := gsm.Status()
+	require.Equal(t, StatusVersion, status.Version)
+	require.Equal(t, int64(5), status.CurrentHeight)
+	require.Equal(t, 3, status.Window.Missed)
+	require.Equal(t, 2, status.Window.Streak)
+	require.Equal(t, int64(3), status.Window.LastSigned)
+	require.Len(t, status.Window.Blocks, 5)
+
+	bz := gsm.GetJsonStatus()
+	require.Contains(t, string(bz), `"version":1`)
+	require.Contains(t, string(bz), `"miss_streak":2`)
+}
+
+func TestGuardStatusTxInvalidReason(t *testing.T) {
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{}, nil)
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	require.Equal(t, "unknown", gsm.Status().TransactionStatus)
+	gsm.ProcessEvent(eventTxValidity{"a", false})
+	gsm.ProcessEvent(eventTxDiagnosis{"a", TxReasonSequenceUsed, "sequence 1 already used"})
+
+	status := gsm.Status()
+	require.Equal(t, "invalid", status.TransactionStatus)
+	require.Equal(t, "sequence_used", status.TransactionInvalidReason)
+	require.Equal(t, "sequence 1 already used", status.TransactionError)
+	bz := gsm.GetJsonStatus()
+	require.Contains(t, string(bz), `"transaction_status":"invalid"`)
+	require.Contains(t, string(bz), `"transaction_invalid_reason":"sequence_used"`)
+
+	gsm.ProcessEvent(eventTxValidity{"a", true})
+	status = gsm.Status()
+	require.Equal(t, "valid", status.TransactionStatus)
+	require.Equal(t, "", status.TransactionInvalidReason)
+}
@@ -0,0 +1,87 @@
+package guard
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// LoopStuckMargin is added to BROADCAST_TIMEOUT: event processing includes broadcast of set_offline
+const LoopStuckMargin = time.Minute
+
+// LoopStuckTimeout is the longest time of one event processing
+func (sm *GuardStateMachine) LoopStuckTimeout() time.Duration {
+	timeout := sm.config.BroadcastTimeout
+	if timeout <= 0 {
+		timeout = DefaultBroadcastTimeout
+	}
+	return time.Duration(timeout)*time.Second + LoopStuckMargin
+}
+
+// Liveness returns error if event loop is not running or is stuck in event processing
+func (sm *GuardStateMachine) Liveness(now time.Time) error {
+	if !sm.isRunning {
+		return fmt.Errorf("event loop is not running")
+	}
+	loopAt := atomic.LoadInt64(&sm.loopAt)
+	if loopAt == 0 {
+		return fmt.Errorf("event loop is not started")
+	}
+	if idle := now.Sub(time.Unix(0, loopAt)); idle > sm.LoopStuckTimeout() {
+		return fmt.Errorf("event loop is stuck for %s", idle.Round(time.Second))
+	}
+	return nil
+}
+
+// Readiness returns reasons why guard can't protect validator, empty if guard is ready
+func (sm *GuardStateMachine) Readiness(now time.Time) []string {
+	sm.stateMu.RLock()
+	defer sm.stateMu.RUnlock()
+	return sm.problems(now)
+}
+
+// problems are ordered by importance: first one is shown as critical in status
+func (sm *GuardStateMachine) problems(now time.Time) []string {
+	var problems []string
+	if sm.summaryValidatorOnline() && sm.summaryTxValidity() == TxInvalid {
+		problems = append(problems, "validator is online and transaction is invalid")
+	}
+	if sm.summaryWatcherState() == WatcherConnecting {
+		problems = append(problems, "watchers are disconnected from nodes")
+	}
+	sm.mu.Lock()
+	lastHeightUpdate := sm.lastHeightUpdate
+	sm.mu.Unlock()
+	if now.Sub(lastHeightUpdate).Seconds() > float64(sm.config.NewBlockTimeout) {
+		problems = append(problems, fmt.Sprintf("last block received more than %d seconds ago", sm.config.NewBlockTimeout))
+	}
+	return problems
+}
+
+// WatcherHealth is state of watcher and its last error if watcher_error alert is open
+type WatcherHealth struct {
+	Node  string `json:"node"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// Watchers returns health of watchers ordered by node
+func (sm *GuardStateMachine) Watchers() []WatcherHealth {
+	sm.stateMu.RLock()
+	defer sm.stateMu.RUnlock()
+	sm.alertsMu.Lock()
+	defer sm.alertsMu.Unlock()
+	watchers := []WatcherHealth{}
+	for node, state := range sm.watchersState {
+		wh := WatcherHealth{Node: node, State: WatcherStateName(state)}
+		if alert, ok := sm.openAlerts[NotifyWatcherError+":"+node]; ok {
+			wh.Error = alert.Message
+		}
+		watchers = append(watchers, wh)
+	}
+	sort.Slice(watchers, func(i, j int) bool {
+		return watchers[i].Node < watchers[j].Node
+	})
+	return watchers
+}
@@ -0,0 +1,32 @@
+package guard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestGuardHealth(t *testing.T) {
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 8, MissedBlocksWindow: 24, NewBlockTimeout: 10}, nil)
+	now := time.Now()
+	require.Error(t, gsm.Liveness(now))
+	gsm.isRunning = true
+	gsm.loopAt = now.UnixNano()
+	require.NoError(t, gsm.Liveness(now.Add(time.Second)))
+	require.Equal(t, 90*time.Second, gsm.LoopStuckTimeout())
+	require.Error(t, gsm.Liveness(now.Add(gsm.LoopStuckTimeout()+time.Second)))
+	gsm.config.BroadcastTimeout = 300
+	require.NoError(t, gsm.Liveness(now.Add(5*time.Minute)))
+
+	require.Equal(t, []string{"watchers are disconnected from nodes"}, gsm.Readiness(now))
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventValidatorState{"a", 1, true})
+	gsm.ProcessEvent(eventTxValidity{"a", false})
+	gsm.SetSign(1, true)
+	require.Equal(t, []string{"validator is online and transaction is invalid"}, gsm.Readiness(time.Now()))
+	gsm.ProcessEvent(eventTxValidity{"a", true})
+	require.Empty(t, gsm.Readiness(time.Now()))
+	require.Equal(t, []string{"last block received more than 10 seconds ago"}, gsm.Readiness(time.Now().Add(11*time.Second)))
+}
@@ -0,0 +1,209 @@
+package guard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is count of events kept in history if EVENT_HISTORY_SIZE is not set
+const DefaultHistorySize = 1000
+
+// types of history events
+const (
+	HistoryWatcherState   = "watcher_state"
+	HistoryWatcherError   = "watcher_error"
+	HistoryWatcherOk      = "watcher_ok" // watcher succeeded after error
+	HistoryTxValidity     = "tx_validity"
+	HistoryTxDiagnosis    = "tx_diagnosis"
+	HistoryValidatorState = "validator_state" // only changes of validator online
+	HistorySkipSign       = "skip_sign"       // missed blocks limit is reached
+	HistorySetOnline      = "set_online"
+	HistorySign           = "sign" // result of block signing, one per height
+	HistoryTransition     = "transition"
+)
+
+// HistorySubscriberCapacity is count of events buffered for stream subscriber, events are dropped for slow subscriber
+const HistorySubscriberCapacity = 100
+
+// HistoryEvent is internal event of guard, source is node of watcher or "guard"
+type HistoryEvent struct {
+	ID        uint64                 `json:"id"`
+	Time      time.Time              `json:"time"`
+	Type      string                 `json:"type"`
+	Source    string                 `json:"source"`
+	Validator string                 `json:"validator"`
+	Height    int64                  `json:"height"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// HistoryFilter selects events with ID greater than SinceID, not older than SinceTime,
+// of one of Types (any if empty) and of Validator (any if empty)
+type HistoryFilter struct {
+	SinceID   uint64
+	SinceTime time.Time
+	Types     []string
+	Validator string
+}
+
+// ParseHistoryFilter parses query parameters: since is event ID or RFC3339 time, types are separated by ','
+func ParseHistoryFilter(since string, types string, validator string) (HistoryFilter, error) {
+	filter := HistoryFilter{Validator: validator}
+	if since > "" {
+		id, err := strconv.ParseUint(since, 10, 64)
+		if err == nil {
+			filter.SinceID = id
+		} else {
+			filter.SinceTime, err = time.Parse(time.RFC3339, since)
+			if err != nil {
+				return filter, fmt.Errorf("since must be event id or RFC3339 time: %s", since)
+			}
+		}
+	}
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.TrimSpace(t); t > "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	return filter, nil
+}
+
+// history is ring buffer of last events, new events are also sent to subscribers
+type history struct {
+	events      []HistoryEvent
+	next        int // index of next event in buffer
+	lastID      uint64
+	subscribers map[chan HistoryEvent]HistoryFilter
+	mu          sync.Mutex
+}
+
+func newHistory(size int) *history {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &history{
+		events:      make([]HistoryEvent, 0, size),
+		subscribers: make(map[chan HistoryEvent]HistoryFilter),
+	}
+}
+
+func (h *history) add(ev HistoryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID++
+	ev.ID = h.lastID
+	for ch, filter := range h.subscribers {
+		if !filter.accept(ev) {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	if len(h.events) < cap(h.events) {
+		h.events = append(h.events, ev)
+		return
+	}
+	h.events[h.next] = ev
+	h.next = (h.next + 1) % len(h.events)
+}
+
+func (h *history) subscribe(filter HistoryFilter) (<-chan HistoryEvent, func()) {
+	ch := make(chan HistoryEvent, HistorySubscriberCapacity)
+	h.mu.Lock()
+	h.subscribers[ch] = filter
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// list returns events in order of occurrence
+func (h *history) list(filter HistoryFilter) []HistoryEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	result := []HistoryEvent{}
+	for i := range h.events {
+		ev := h.events[(h.next+i)%len(h.events)]
+		if !filter.accept(ev) {
+			continue
+		}
+		result = append(result, ev)
+	}
+	return result
+}
+
+func (f HistoryFilter) accept(ev HistoryEvent) bool {
+	if ev.ID <= f.SinceID || ev.Time.Before(f.SinceTime) {
+		return false
+	}
+	if f.Validator > "" && !strings.EqualFold(f.Validator, ev.Validator) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == ev.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Events returns events from history
+func (sm *GuardStateMachine) Events(filter HistoryFilter) []HistoryEvent {
+	return sm.history.list(filter)
+}
+
+// SubscribeEvents returns channel of new events accepted by filter and function to unsubscribe,
+// events are dropped if channel is full
+func (sm *GuardStateMachine) SubscribeEvents(filter HistoryFilter) (<-chan HistoryEvent, func()) {
+	return sm.history.subscribe(filter)
+}
+
+func (sm *GuardStateMachine) record(typ string, source string, height int64, details map[string]interface{}) {
+	sm.history.add(HistoryEvent{
+		Time:      time.Now(),
+		Type:      typ,
+		Source:    source,
+		Validator: sm.config.ValidatorAddress,
+		Height:    height,
+		Details:   details,
+	})
+}
+
+// recordEvent adds event of state machine loop to history, it is called before processing of event
+func (sm *GuardStateMachine) recordEvent(ev interface{}) {
+	switch e := ev.(type) {
+	case eventWatcherState:
+		sm.record(HistoryWatcherState, e.node, sm.currentHeight, map[string]interface{}{
+			"state": WatcherStateName(e.state),
+		})
+	case eventWatcherError:
+		sm.record(HistoryWatcherError, e.node, sm.currentHeight, map[string]interface{}{"error": e.err})
+	case eventWatcherOk:
+		sm.record(HistoryWatcherOk, e.node, sm.currentHeight, nil)
+	case eventTxValidity:
+		sm.record(HistoryTxValidity, e.node, sm.currentHeight, map[string]interface{}{"valid": e.valid})
+	case eventTxDiagnosis:
+		sm.record(HistoryTxDiagnosis, e.node, sm.currentHeight, map[string]interface{}{
+			"reason":  TxReasonName(e.reason),
+			"details": e.details,
+		})
+	case eventValidatorState:
+		if e.height < sm.currentHeight || e.online == sm.isValidatorOnline {
+			return
+		}
+		sm.record(HistoryValidatorState, e.node, e.height, map[string]interface{}{"online": e.online})
+	case eventValidatorSkipSign:
+		sm.record(HistorySkipSign, "guard", sm.currentHeight, nil)
+	case eventSetOnline:
+		sm.record(HistorySetOnline, "guard", sm.currentHeight, nil)
+	}
+}
@@ -0,0 +1,63 @@
+package guard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestHistory(t *testing.T) {
+	h := newHistory(3)
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		h.add(HistoryEvent{Time: start.Add(time.Duration(i) * time.Second), Type: HistorySign, Height: int64(i)})
+	}
+	events := h.list(HistoryFilter{})
+	require.Len(t, events, 3)
+	require.Equal(t, []uint64{3, 4, 5}, []uint64{events[0].ID, events[1].ID, events[2].ID})
+	require.Len(t, h.list(HistoryFilter{SinceID: 4}), 1)
+	require.Len(t, h.list(HistoryFilter{SinceTime: start.Add(4 * time.Second)}), 2)
+	require.Len(t, h.list(HistoryFilter{Types: []string{HistoryTransition}}), 0)
+
+	filter, err := ParseHistoryFilter("2022-11-21T10:00:00Z", "sign, transition", "")
+	require.NoError(t, err)
+	require.Equal(t, []string{HistorySign, HistoryTransition}, filter.Types)
+	filter, err = ParseHistoryFilter("12", "", "")
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), filter.SinceID)
+	_, err = ParseHistoryFilter("yesterday", "", "")
+	require.Error(t, err)
+}
+
+func TestGuardHistory(t *testing.T) {
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 8, MissedBlocksWindow: 24}, nil)
+	gsm.isRunning = true
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventValidatorState{"a", 1, true})
+	gsm.ProcessEvent(eventValidatorState{"a", 2, true}) // not changed
+	gsm.SetSign(2, false)
+
+	var types []string
+	for _, ev := range gsm.Events(HistoryFilter{}) {
+		types = append(types, ev.Type)
+	}
+	require.Equal(t, []string{HistoryWatcherState, HistoryTransition, HistoryValidatorState, HistoryTransition, HistorySign}, types)
+	sign := gsm.Events(HistoryFilter{Types: []string{HistorySign}})[0]
+	require.Equal(t, int64(2), sign.Height)
+	require.Equal(t, false, sign.Details["signed"])
+}
+
+func TestHistorySubscribe(t *testing.T) {
+	h := newHistory(10)
+	events, unsubscribe := h.subscribe(HistoryFilter{Types: []string{HistoryTransition}, Validator: "aa"})
+	h.add(HistoryEvent{Type: HistorySign, Validator: "AA"})
+	h.add(HistoryEvent{Type: HistoryTransition, Validator: "AA"})
+	h.add(HistoryEvent{Type: HistoryTransition, Validator: "BB"})
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(2), (<-events).ID)
+	unsubscribe()
+	h.add(HistoryEvent{Type: HistoryTransition, Validator: "AA"})
+	require.Len(t, events, 0)
+}
@@ -0,0 +1,61 @@
+package guard
+
+import (
+	"fmt"
+	"io"
+
+	kitlog "github.com/go-kit/log"
+	kitlevel "github.com/go-kit/log/level"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	LogFormatPlain  = "plain"  // tendermint console format
+	LogFormatLogfmt = "logfmt" // key=value
+	LogFormatJson   = "json"
+)
+
+// NewLogger creates logger with LOG_FORMAT and LOG_LEVEL from config, empty values mean plain format and info level
+func NewLogger(config Config, w io.Writer) (tmlog.Logger, error) {
+	var logger tmlog.Logger
+	switch config.LogFormat {
+	case "", LogFormatPlain:
+		logger = tmlog.NewTMLogger(w)
+	case LogFormatLogfmt:
+		logger = &logfmtLogger{kitlog.With(kitlog.NewLogfmtLogger(kitlog.NewSyncWriter(w)), "ts", kitlog.DefaultTimestampUTC)}
+	case LogFormatJson:
+		logger = tmlog.NewTMJSONLogger(w)
+	default:
+		return nil, fmt.Errorf("unknown log format '%s', expected plain, logfmt or json", config.LogFormat)
+	}
+	level := config.LogLevel
+	if level == "" {
+		level = "info"
+	}
+	option, err := tmlog.AllowLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return tmlog.NewFilter(logger, option), nil
+}
+
+// logfmtLogger writes plain logfmt lines: ts=... level=... msg=... key=value
+type logfmtLogger struct {
+	kitlog.Logger
+}
+
+func (l *logfmtLogger) Debug(msg string, keyvals ...interface{}) {
+	kitlevel.Debug(l.Logger).Log(append([]interface{}{"msg", msg}, keyvals...)...)
+}
+
+func (l *logfmtLogger) Info(msg string, keyvals ...interface{}) {
+	kitlevel.Info(l.Logger).Log(append([]interface{}{"msg", msg}, keyvals...)...)
+}
+
+func (l *logfmtLogger) Error(msg string, keyvals ...interface{}) {
+	kitlevel.Error(l.Logger).Log(append([]interface{}{"msg", msg}, keyvals...)...)
+}
+
+func (l *logfmtLogger) With(keyvals ...interface{}) tmlog.Logger {
+	return &logfmtLogger{kitlog.With(l.Logger, keyvals...)}
+}
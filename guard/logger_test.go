@@ -0,0 +1,35 @@
+package guard
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(Config{LogFormat: LogFormatJson, LogLevel: "info"}, &buf)
+	require.NoError(t, err)
+	logger = logger.With("node", "a")
+	logger.Debug("skipped")
+	logger.Info("retrieved signatures", "height", 10)
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "a", line["node"])
+	require.Equal(t, 10.0, line["height"])
+
+	buf.Reset()
+	logger, err = NewLogger(Config{LogFormat: LogFormatLogfmt, LogLevel: "debug"}, &buf)
+	require.NoError(t, err)
+	logger.With("node", "a").Debug("retrieved signatures", "height", 10)
+	require.True(t, strings.HasPrefix(buf.String(), "level=debug ts="), buf.String())
+	require.True(t, strings.HasSuffix(buf.String(), ` node=a msg="retrieved signatures" height=10`+"\n"), buf.String())
+
+	_, err = NewLogger(Config{LogFormat: "xml"}, &buf)
+	require.Error(t, err)
+	_, err = NewLogger(Config{LogLevel: "trace"}, &buf)
+	require.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package guard
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+)
+
+const metricsNamespace = "dsc_guard"
+
+var (
+	globalStates  = []GlobalState{StateStarting, StateConnecting, StateWatching, StateValidatorIsOffline, StateWatchingWithoutTx}
+	watcherStates = []WatcherState{WatcherConnecting, WatcherQueryValidator, WatcherWatching}
+	txStatuses    = map[TxState]string{TxUnknown: "unknown", TxInvalid: "invalid", TxValid: "valid"}
+)
+
+// Metrics exposes guard state for Prometheus: state machine values are collected on scrape,
+// watchers update their own metrics. Nil *Metrics is valid and does nothing.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	watcherHeight *prometheus.GaugeVec
+	watcherErrors *prometheus.CounterVec
+	rpcLatency    *prometheus.HistogramVec
+}
+
+func NewMetrics(sm *GuardStateMachine) *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		watcherHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "watcher_last_height",
+			Help:      "Last block height received by watcher.",
+		}, []string{"node"}),
+		watcherErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "watcher_errors_total",
+			Help:      "Connection and query errors of watcher.",
+		}, []string{"node"}),
+		rpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_request_duration_seconds",
+			Help:      "Duration of Tendermint RPC requests.",
+			Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"node", "method", "result"}),
+	}
+	m.Registry.MustRegister(
+		m.watcherHeight,
+		m.watcherErrors,
+		m.rpcLatency,
+		&stateCollector{sm: sm},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+func (m *Metrics) WatcherHeight(node string, height int64) {
+	if m == nil {
+		return
+	}
+	m.watcherHeight.WithLabelValues(node).Set(float64(height))
+}
+
+func (m *Metrics) WatcherError(node string) {
+	if m == nil {
+		return
+	}
+	m.watcherErrors.WithLabelValues(node).Inc()
+}
+
+// RpcObserver returns observer of fastclient requests to node
+func (m *Metrics) RpcObserver(node string) fastclient.Observer {
+	if m == nil {
+		return nil
+	}
+	return func(method string, duration time.Duration, err error) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		m.rpcLatency.WithLabelValues(node, method, result).Observe(duration.Seconds())
+	}
+}
+
+var (
+	descState          = prometheus.NewDesc(metricsNamespace+"_state", "Current state of guard (1 for current state).", []string{"state"}, nil)
+	descHeight         = prometheus.NewDesc(metricsNamespace+"_current_height", "Current block height.", nil, nil)
+	descMissedBlocks   = prometheus.NewDesc(metricsNamespace+"_missed_blocks", "Missed blocks in window.", nil, nil)
+	descMissedLimit    = prometheus.NewDesc(metricsNamespace+"_missed_blocks_limit", "Missed blocks in window to send set_offline.", nil, nil)
+	descTxStatus       = prometheus.NewDesc(metricsNamespace+"_tx_status", "Validity of set_offline transaction (1 for current status).", []string{"status"}, nil)
+	descValidatorOn    = prometheus.NewDesc(metricsNamespace+"_validator_online", "1 if validator is online.", nil, nil)
+	descWatcherState   = prometheus.NewDesc(metricsNamespace+"_watcher_state", "State of watcher (1 for current state).", []string{"node", "state"}, nil)
+	descSetOfflineSent = prometheus.NewDesc(metricsNamespace+"_set_offline_broadcasts_total", "Count of set_offline broadcasts.", nil, nil)
+)
+
+// stateCollector reads state machine on every scrape
+type stateCollector struct {
+	sm *GuardStateMachine
+}
+
+func (sc *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{descState, descHeight, descMissedBlocks, descMissedLimit, descTxStatus,
+		descValidatorOn, descWatcherState, descSetOfflineSent} {
+		ch <- d
+	}
+}
+
+func (sc *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	sm := sc.sm
+	sm.mu.Lock()
+	height, missed := sm.currentHeight, sm.missedBlocks
+	sm.mu.Unlock()
+	sm.stateMu.RLock()
+	defer sm.stateMu.RUnlock()
+	for _, state := range globalStates {
+		ch <- prometheus.MustNewConstMetric(descState, prometheus.GaugeValue, boolValue(sm.state == state), StateName(state))
+	}
+	ch <- prometheus.MustNewConstMetric(descHeight, prometheus.GaugeValue, float64(height))
+	ch <- prometheus.MustNewConstMetric(descMissedBlocks, prometheus.GaugeValue, float64(missed))
+	ch <- prometheus.MustNewConstMetric(descMissedLimit, prometheus.GaugeValue, float64(sm.config.MissedBlocksLimit))
+	txValidity := sm.summaryTxValidity()
+	for status, name := range txStatuses {
+		ch <- prometheus.MustNewConstMetric(descTxStatus, prometheus.GaugeValue, boolValue(txValidity == status), name)
+	}
+	ch <- prometheus.MustNewConstMetric(descValidatorOn, prometheus.GaugeValue, boolValue(sm.summaryValidatorOnline()))
+	for node, ws := range sm.watchersState {
+		for _, state := range watcherStates {
+			ch <- prometheus.MustNewConstMetric(descWatcherState, prometheus.GaugeValue, boolValue(ws == state), node, WatcherStateName(state))
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(descSetOfflineSent, prometheus.CounterValue, float64(sm.setOfflineCount))
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
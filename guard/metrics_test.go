@@ -0,0 +1,32 @@
+package guard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestMetrics(t *testing.T) {
+	gsm := NewGuardState(tmlog.NewTMLogger(dummyWriter{}), Config{MissedBlocksLimit: 8}, nil)
+	gsm.ProcessEvent(eventWatcherState{"a", WatcherWatching})
+	gsm.ProcessEvent(eventTxValidity{"a", true})
+	gsm.NotifySetOffline(BroadcastReport{})
+	m := NewMetrics(gsm)
+	m.WatcherHeight("a", 100)
+	m.WatcherError("a")
+	m.RpcObserver("a")("status", time.Millisecond, errors.New("timeout"))
+
+	require.Equal(t, 100.0, testutil.ToFloat64(m.watcherHeight.WithLabelValues("a")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.watcherErrors.WithLabelValues("a")))
+	require.Equal(t, 1, testutil.CollectAndCount(m.rpcLatency))
+	// 5 states, height, missed, limit, 3 tx statuses, validator online, 3 watcher states, broadcasts
+	require.Equal(t, 16, testutil.CollectAndCount(&stateCollector{sm: gsm}))
+
+	var nilMetrics *Metrics
+	nilMetrics.WatcherError("a")
+	require.Nil(t, nilMetrics.RpcObserver("a"))
+}
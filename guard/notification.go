@@ -0,0 +1,244 @@
+package guard
+
+import (
+	"fmt"
+	"time"
+)
+
+type NotificationType = string
+type Severity = string
+
+const (
+	NotifyStateChanged      NotificationType = "state_changed"
+	NotifyWatchingWithoutTx NotificationType = "watching_without_tx" // guard can't protect validator
+	NotifyWatchersDown      NotificationType = "watchers_down"       // all watchers lost nodes
+	NotifyWatcherDown       NotificationType = "watcher_down"        // one watcher lost node
+	NotifyTxInvalid         NotificationType = "tx_invalid"
+	NotifyMissedBlocks      NotificationType = "missed_blocks" // warning threshold of missed blocks is reached
+	NotifySetOffline        NotificationType = "set_offline"
+	NotifyWatcherError      NotificationType = "watcher_error"
+	NotifyUnprotected       NotificationType = "unprotected" // validator is online and transaction is invalid
+	NotifyDigest            NotificationType = "digest"      // periodic uptime report
+)
+
+// dedup key of alert which has no own notification type
+const AlertValidatorOffline = "validator_offline"
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Notification is an event important for validator operator. Notification with Key is an alert:
+// condition which lasts until notification with the same Key and Resolved
+type Notification struct {
+	Type      NotificationType       `json:"type"`
+	Key       string                 `json:"key,omitempty"`
+	Resolved  bool                   `json:"resolved,omitempty"`
+	Severity  Severity               `json:"severity"`
+	Validator string                 `json:"validator"`
+	Height    int64                  `json:"height"`
+	State     string                 `json:"state"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Time      time.Time              `json:"time"`
+}
+
+// Notifier delivers notifications; Notify is called from state machine loop and must not block
+type Notifier interface {
+	Notify(n Notification)
+}
+
+func StateName(state GlobalState) string {
+	switch state {
+	case StateStarting:
+		return "starting"
+	case StateConnecting:
+		return "connecting"
+	case StateWatching:
+		return "watching"
+	case StateValidatorIsOffline:
+		return "validator_offline"
+	case StateWatchingWithoutTx:
+		return "watching_without_tx"
+	}
+	return "unknown"
+}
+
+// SetNotifier must be called before Start
+func (sm *GuardStateMachine) SetNotifier(notifier Notifier) {
+	sm.notifier = notifier
+}
+
+func (sm *GuardStateMachine) notify(typ NotificationType, severity Severity, message string, details map[string]interface{}) {
+	sm.send(Notification{Type: typ, Severity: severity, Message: message, Details: details})
+}
+
+func (sm *GuardStateMachine) send(n Notification) {
+	if sm.notifier == nil {
+		return
+	}
+	n.Validator = sm.config.ValidatorAddress
+	n.Height = sm.currentHeight
+	n.State = StateName(sm.state)
+	n.Time = time.Now()
+	sm.notifier.Notify(n)
+}
+
+// fire reports condition which lasts until resolve with the same key,
+// it is sent on every occurrence: alert manager removes duplicates
+func (sm *GuardStateMachine) fire(key string, typ NotificationType, severity Severity, message string, details map[string]interface{}) {
+	n := Notification{Key: key, Type: typ, Severity: severity, Message: message, Details: details}
+	sm.alertsMu.Lock()
+	sm.openAlerts[key] = n
+	sm.alertsMu.Unlock()
+	sm.send(n)
+}
+
+// resolve reports that condition is cleared, nothing is sent if alert with the key is not open
+func (sm *GuardStateMachine) resolve(key string) {
+	sm.alertsMu.Lock()
+	n, ok := sm.openAlerts[key]
+	delete(sm.openAlerts, key)
+	sm.alertsMu.Unlock()
+	if !ok {
+		return
+	}
+	n.Resolved = true
+	n.Message = "resolved: " + n.Message
+	n.Details = nil
+	sm.send(n)
+}
+
+// transition changes state of guard and notifies about it
+func (sm *GuardStateMachine) transition(to GlobalState) {
+	from := sm.state
+	sm.logger.Info("guard state transition", "from", StateName(from), "to", StateName(to), "height", sm.currentHeight)
+	sm.state = to
+	sm.record(HistoryTransition, "guard", sm.currentHeight, map[string]interface{}{"from": StateName(from), "to": StateName(to)})
+	sm.auditTransition(from, to)
+	switch from {
+	case StateWatchingWithoutTx:
+		sm.resolve(NotifyWatchingWithoutTx)
+		sm.resolve(NotifyUnprotected)
+	case StateConnecting:
+		sm.resolve(NotifyWatchersDown)
+	case StateValidatorIsOffline:
+		sm.resolve(AlertValidatorOffline)
+	}
+	details := map[string]interface{}{"from": StateName(from), "to": StateName(to)}
+	switch to {
+	case StateWatchingWithoutTx:
+		reason, txError := sm.summaryTxDiagnosis()
+		details["validator_online"] = sm.summaryValidatorOnline()
+		details["reason"] = TxReasonName(reason)
+		details["transaction_error"] = txError
+		sm.fire(NotifyWatchingWithoutTx, NotifyWatchingWithoutTx, SeverityCritical, "guard is watching without valid set_offline transaction, validator is not protected", details)
+		sm.notifyUnprotected()
+	case StateConnecting:
+		if from == StateStarting {
+			sm.notify(NotifyStateChanged, SeverityInfo, "guard is connecting to nodes", details)
+			return
+		}
+		sm.fire(NotifyWatchersDown, NotifyWatchersDown, SeverityCritical, "all watchers are disconnected from nodes", details)
+	case StateValidatorIsOffline:
+		sm.fire(AlertValidatorOffline, NotifyStateChanged, SeverityWarning, "validator is offline", details)
+	default:
+		sm.notify(NotifyStateChanged, SeverityInfo, fmt.Sprintf("guard state is %s", StateName(to)), details)
+	}
+}
+
+// notifyTxDiagnosis fires alert for reason of invalid transaction, alert of previous reason is resolved
+func (sm *GuardStateMachine) notifyTxDiagnosis() {
+	if sm.summaryTxValidity() != TxInvalid {
+		return
+	}
+	reason, details := sm.summaryTxDiagnosis()
+	key := NotifyTxInvalid + ":" + TxReasonName(reason)
+	if sm.txAlertKey != key {
+		sm.resolve(sm.txAlertKey)
+		sm.txAlertKey = key
+	}
+	sm.fire(key, NotifyTxInvalid, SeverityCritical, "set_offline transaction is invalid: "+TxReasonName(reason), map[string]interface{}{
+		"reason":            TxReasonName(reason),
+		"transaction_error": details,
+	})
+}
+
+// notifyUnprotected logs error and fires alert once when validator is online and transaction is invalid,
+// alert is resolved on exit from watching_without_tx
+func (sm *GuardStateMachine) notifyUnprotected() {
+	if !sm.summaryValidatorOnline() || sm.summaryTxValidity() != TxInvalid {
+		return
+	}
+	sm.alertsMu.Lock()
+	_, open := sm.openAlerts[NotifyUnprotected]
+	sm.alertsMu.Unlock()
+	if open {
+		return
+	}
+	sm.logger.Error("validator is online, but transaction is invalid! You can't protect validator from slash", "state", StateName(sm.state))
+	sm.fire(NotifyUnprotected, NotifyUnprotected, SeverityCritical, "validator is online, but transaction is invalid, validator is not protected from slash", nil)
+}
+
+// resolveTxDiagnosis is called when transaction is not invalid anymore
+func (sm *GuardStateMachine) resolveTxDiagnosis() {
+	if sm.txAlertKey == "" {
+		return
+	}
+	sm.resolve(sm.txAlertKey)
+	sm.txAlertKey = ""
+}
+
+// notifyMissedBlocks fires alert for every reached warning threshold and resolves others
+func (sm *GuardStateMachine) notifyMissedBlocks(missed int) {
+	level := 0
+	for _, percent := range sm.warnings {
+		key := fmt.Sprintf("%s:%d", NotifyMissedBlocks, percent)
+		if missed < WarningBlocks(sm.config.MissedBlocksLimit, percent) {
+			sm.resolve(key)
+			continue
+		}
+		level = percent
+		sm.fire(key, NotifyMissedBlocks, SeverityWarning,
+			fmt.Sprintf("validator missed %d blocks of %d (%d%% of limit), set_offline is sent at %d", missed, sm.config.MissedBlocksWindow, percent, sm.config.MissedBlocksLimit),
+			map[string]interface{}{"missed": missed, "limit": sm.config.MissedBlocksLimit, "window": sm.config.MissedBlocksWindow, "threshold": percent})
+	}
+	sm.missedBlocks = missed
+	sm.warningLevel = level
+}
+
+// NotifySetOffline reports result of set_offline broadcast, it is called by set_offline callback outside of event processing
+func (sm *GuardStateMachine) NotifySetOffline(report BroadcastReport) {
+	sm.stateMu.Lock()
+	defer sm.stateMu.Unlock()
+	sm.setOfflineCount++
+	sm.writeAudit(AuditRecord{Type: AuditBroadcast, Tx: "set_offline", Broadcast: &report})
+	sm.digest.addTrigger(DigestTrigger{
+		Time:     time.Now(),
+		Height:   sm.currentHeight,
+		TxHash:   report.TxHash,
+		Accepted: report.Accepted(),
+		Included: report.Included,
+	})
+	severity, message := SeverityCritical, "set_offline transaction is sent"
+	if !report.Included {
+		message = "set_offline transaction is sent, but it is not in block"
+	}
+	if report.Failed {
+		message = "set_offline transaction is in block, but it failed"
+	}
+	if !report.Accepted() {
+		message = "set_offline transaction is rejected by all nodes"
+	}
+	sm.notify(NotifySetOffline, severity, message, map[string]interface{}{
+		"tx_hash":  report.TxHash,
+		"included": report.Included,
+		"failed":   report.Failed,
+		"accepted": report.Accepted(),
+		"height":   report.Height,
+		"node":     report.Node,
+		"outcomes": report.Outcomes,
+	})
+}
@@ -0,0 +1,56 @@
+package guard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+)
+
+// validator node is not ready to sign if its last block is older
+const MaxValidatorNodeLag = time.Minute
+
+// DefaultGracePeriodDuration is grace period after set_online in blocks
+const DefaultGracePeriodDuration = 15840
+
+// CheckValidatorNode checks that validator's own node is synced and has validator key to sign blocks
+func CheckValidatorNode(node string, config Config) (fastclient.NodeStatus, error) {
+	client := fastclient.NewFastClient(node, time.Duration(config.NewBlockTimeout)*time.Second)
+	status, err := client.Status()
+	if err != nil {
+		return status, fmt.Errorf("query status of validator node %s: %s", node, err.Error())
+	}
+	if status.CatchingUp {
+		return status, fmt.Errorf("validator node %s is catching up, height %d", node, status.LatestBlockHeight)
+	}
+	if lag := time.Since(status.LatestBlockTime); lag > MaxValidatorNodeLag {
+		return status, fmt.Errorf("validator node %s last block %d is %s old", node, status.LatestBlockHeight, lag.Truncate(time.Second))
+	}
+	if !strings.EqualFold(status.ValidatorAddress, config.ValidatorAddress) {
+		return status, fmt.Errorf("validator node %s signs as %s, expected %s", node, status.ValidatorAddress, config.ValidatorAddress)
+	}
+	return status, nil
+}
+
+// StartGracePeriod must be called after set_online transaction is in block:
+// sign window is cleared and set_offline is not sent during grace period
+func (sm *GuardStateMachine) StartGracePeriod() {
+	if !sm.isRunning {
+		return
+	}
+	sm.eventChannel <- eventSetOnline{}
+}
+
+func (sm *GuardStateMachine) processSetOnline() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.ResetWindow()
+	sm.isSkipSign = false
+	if !sm.config.EnableGracePeriod {
+		sm.logger.Info("validator is set online, protection is re-armed", "height", sm.currentHeight)
+		return
+	}
+	sm.graceUntil = sm.currentHeight + int64(sm.config.GracePeriodDuration)
+	sm.logger.Info("validator is set online, grace period is started", "height", sm.currentHeight, "grace_until", sm.graceUntil)
+}
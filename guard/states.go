@@ -23,3 +23,46 @@ const (
 	TxInvalid
 	TxValid
 )
+
+type TxInvalidReason = uint
+
+// reasons why set_offline transaction is rejected by node (see Watcher.diagnoseTx)
+const (
+	TxReasonUnknown TxInvalidReason = iota
+	TxReasonSequenceUsed
+	TxReasonSequenceAhead
+	TxReasonWrongChainID
+	TxReasonWrongAccountNumberOrChainID // signature does not match, both are only in signed bytes, so can't tell which of them is wrong
+	TxReasonInsufficientFee
+	TxReasonBadSignature
+)
+
+func TxReasonName(reason TxInvalidReason) string {
+	switch reason {
+	case TxReasonSequenceUsed:
+		return "sequence_used"
+	case TxReasonSequenceAhead:
+		return "sequence_ahead"
+	case TxReasonWrongChainID:
+		return "wrong_chain_id"
+	case TxReasonWrongAccountNumberOrChainID:
+		return "wrong_account_number_or_chain_id"
+	case TxReasonInsufficientFee:
+		return "insufficient_fee"
+	case TxReasonBadSignature:
+		return "bad_signature"
+	}
+	return "unknown"
+}
+
+func WatcherStateName(state WatcherState) string {
+	switch state {
+	case WatcherConnecting:
+		return "connecting"
+	case WatcherQueryValidator:
+		return "query_validator"
+	case WatcherWatching:
+		return "watching"
+	}
+	return "unknown"
+}
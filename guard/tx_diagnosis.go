@@ -0,0 +1,119 @@
+package guard
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+// "account sequence mismatch, expected 5, got 4: incorrect account sequence"
+var sequenceMismatchRe = regexp.MustCompile(`expected (\d+), got (\d+)`)
+
+// diagnoseTx explains why CheckTx failed, query errors are logged
+func (w *Watcher) diagnoseTx(res fastclient.CheckTxResult) (TxInvalidReason, string) {
+	reason, details, err := DiagnoseTx(w.client, w.txData, res, w.txWasValid)
+	if err != nil {
+		w.logger.Error("diagnose set_offline transaction", "err", err.Error())
+	}
+	return reason, details
+}
+
+// DiagnoseTx queries operator account and node chain-id to explain why CheckTx failed.
+// On query error classification falls back to CheckTx result only, error is returned too.
+func DiagnoseTx(client *fastclient.FastClient, txData []byte, res fastclient.CheckTxResult, wasValid bool) (TxInvalidReason, string, error) {
+	info, err := txdata.Decode(txData)
+	if err != nil {
+		reason, details := classifyTxFailure(res, nil, nil, "", false)
+		return reason, details, err
+	}
+	address := info.Signer
+	if info.Validator > "" {
+		address, err = txdata.OperatorAccount(info.Validator)
+		if err != nil {
+			reason, details := classifyTxFailure(res, &info, nil, "", false)
+			return reason, details, err
+		}
+	}
+	acc, err := txdata.QueryAccount(client, address)
+	if err != nil {
+		reason, details := classifyTxFailure(res, &info, nil, "", false)
+		return reason, details, err
+	}
+	status, err := client.Status()
+	if err != nil {
+		reason, details := classifyTxFailure(res, &info, &acc, "", wasValid)
+		return reason, details, err
+	}
+	reason, details := classifyTxFailure(res, &info, &acc, status.Network, wasValid)
+	return reason, details, nil
+}
+
+// classifyTxFailure is pure part of diagnoseTx.
+// wasValid means that the same transaction passed CheckTx earlier.
+func classifyTxFailure(res fastclient.CheckTxResult, info *txdata.TxInfo, acc *txdata.Account, chainID string, wasValid bool) (TxInvalidReason, string) {
+	if res.Codespace != sdkerrors.RootCodespace {
+		return TxReasonUnknown, res.Log
+	}
+	switch uint32(res.Code) {
+	case sdkerrors.ErrInsufficientFee.ABCICode(), sdkerrors.ErrInsufficientFunds.ABCICode():
+		return TxReasonInsufficientFee, res.Log
+	case sdkerrors.ErrInvalidChainID.ABCICode():
+		return TxReasonWrongChainID, res.Log
+	case sdkerrors.ErrWrongSequence.ABCICode():
+		return classifySequence(res, info, acc)
+	case sdkerrors.ErrUnauthorized.ABCICode(), sdkerrors.ErrInvalidPubKey.ABCICode():
+		return classifySignature(res, info, acc, chainID, wasValid)
+	}
+	return TxReasonUnknown, res.Log
+}
+
+func classifySequence(res fastclient.CheckTxResult, info *txdata.TxInfo, acc *txdata.Account) (TxInvalidReason, string) {
+	var expected, got uint64
+	if info != nil && acc != nil {
+		expected, got = acc.Sequence, info.Sequence
+	} else {
+		m := sequenceMismatchRe.FindStringSubmatch(res.Log)
+		if m == nil {
+			return TxReasonUnknown, res.Log
+		}
+		expected, _ = strconv.ParseUint(m[1], 10, 64)
+		got, _ = strconv.ParseUint(m[2], 10, 64)
+	}
+	if got < expected {
+		return TxReasonSequenceUsed, fmt.Sprintf("sequence %d already used, account sequence is %d", got, expected)
+	}
+	if got > expected {
+		return TxReasonSequenceAhead, fmt.Sprintf("sequence %d is ahead of account sequence %d", got, expected)
+	}
+	return TxReasonUnknown, res.Log
+}
+
+func classifySignature(res fastclient.CheckTxResult, info *txdata.TxInfo, acc *txdata.Account, chainID string, wasValid bool) (TxInvalidReason, string) {
+	if info == nil || acc == nil {
+		return TxReasonUnknown, res.Log
+	}
+	pubKey := info.PubKey
+	if pubKey == nil {
+		pubKey = acc.PubKey
+	}
+	if info.Signer > "" && info.Signer != acc.Address {
+		return TxReasonBadSignature, fmt.Sprintf("transaction is signed by %s, expected operator account %s", info.Signer, acc.Address)
+	}
+	if pubKey == nil || chainID == "" {
+		return TxReasonUnknown, res.Log
+	}
+	if info.VerifySignature(pubKey, chainID, acc.AccountNumber) {
+		// signature is fine, node rejected transaction by other reason
+		return TxReasonUnknown, res.Log
+	}
+	// account number never changes, so previously valid signature points to chain-id change (upgrade)
+	if wasValid {
+		return TxReasonWrongChainID, fmt.Sprintf("transaction was valid earlier, but not for current chain-id %s", chainID)
+	}
+	return TxReasonWrongAccountNumberOrChainID, fmt.Sprintf("signature does not match account number %d and chain-id %s", acc.AccountNumber, chainID)
+}
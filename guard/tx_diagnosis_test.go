@@ -0,0 +1,67 @@
+package guard
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+	"bitbucket.org/decimalteam/dsc-guard/keys"
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+const testMnemonic = "bulb raw claw magnet romance jaguar life cluster solve random laptop salmon pottery subject country aware actual hope wedding hawk amused cage secret network"
+
+func buildTestTx(t *testing.T, chainID string, accountNumber, sequence uint64) (txdata.TxInfo, string) {
+	signer, err := keys.FromMnemonic(testMnemonic)
+	require.NoError(t, err)
+	data := txdata.SignerData{ChainID: chainID, AccountNumber: accountNumber, Sequence: sequence}
+	bz, err := txdata.BuildSetOffline(signer, data, sdk.NewCoin("del", sdk.NewInt(0)))
+	require.NoError(t, err)
+	info, err := txdata.Decode(bz)
+	require.NoError(t, err)
+	address, err := txdata.SignerAddress(signer)
+	require.NoError(t, err)
+	return info, address
+}
+
+func TestClassifyTxFailure(t *testing.T) {
+	info, address := buildTestTx(t, "decimal_2020-1", 12, 4)
+	operator, err := txdata.OperatorAccount(info.Validator)
+	require.NoError(t, err)
+	require.Equal(t, address, operator)
+	require.Equal(t, address, info.Signer)
+
+	acc := txdata.Account{Address: address, AccountNumber: 12, Sequence: 5}
+	seqErr := fastclient.CheckTxResult{Code: 32, Codespace: "sdk", Log: "account sequence mismatch, expected 5, got 4: incorrect account sequence"}
+	authErr := fastclient.CheckTxResult{Code: 4, Codespace: "sdk", Log: "signature verification failed"}
+
+	reason, _ := classifyTxFailure(seqErr, &info, &acc, "decimal_2020-1", false)
+	require.Equal(t, TxReasonSequenceUsed, reason)
+	// without queries: sequences from log
+	reason, _ = classifyTxFailure(seqErr, nil, nil, "", false)
+	require.Equal(t, TxReasonSequenceUsed, reason)
+	acc.Sequence = 3
+	reason, _ = classifyTxFailure(seqErr, &info, &acc, "decimal_2020-1", false)
+	require.Equal(t, TxReasonSequenceAhead, reason)
+
+	reason, _ = classifyTxFailure(fastclient.CheckTxResult{Code: 13, Codespace: "sdk"}, &info, &acc, "decimal_2020-1", false)
+	require.Equal(t, TxReasonInsufficientFee, reason)
+
+	// signature matches, so it's not signature problem
+	reason, _ = classifyTxFailure(authErr, &info, &acc, "decimal_2020-1", false)
+	require.Equal(t, TxReasonUnknown, reason)
+	reason, _ = classifyTxFailure(authErr, &info, &acc, "decimal_2020-2", false)
+	require.Equal(t, TxReasonWrongAccountNumberOrChainID, reason)
+	reason, _ = classifyTxFailure(authErr, &info, &acc, "decimal_2020-2", true)
+	require.Equal(t, TxReasonWrongChainID, reason)
+	acc.AccountNumber = 13
+	reason, _ = classifyTxFailure(authErr, &info, &acc, "decimal_2020-1", false)
+	require.Equal(t, TxReasonWrongAccountNumberOrChainID, reason)
+
+	other := acc
+	other.Address = "d01qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq"
+	reason, _ = classifyTxFailure(authErr, &info, &other, "decimal_2020-1", false)
+	require.Equal(t, TxReasonBadSignature, reason)
+}
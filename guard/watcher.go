@@ -20,13 +20,24 @@ type Watcher struct {
 	guard     Guarder
 	isRunning bool
 
-	client *fastclient.FastClient
-	logger tmlog.Logger
+	client  *fastclient.FastClient
+	logger  tmlog.Logger
+	metrics *Metrics
 
 	lastValidatorHeight int64
 	lastSignatureHeight int64
 	muSetLastHeight     sync.Mutex
 
+	info   WatcherInfo // details for /watchers, see Info
+	infoMu sync.Mutex
+
+	txWasValid bool // current txData passed CheckTx at least once
+
+	// sources of reported error, watcher_error alert is resolved when both succeed (see reportOk)
+	pollFailed    bool
+	txCheckFailed bool
+	errorReported bool
+
 	// this mutex need to avoid transaction check in same time for different watchers
 	cLock *CooldownLock
 }
@@ -37,8 +48,9 @@ func NewWatcher(node string, config Config, guard Guarder, logger tmlog.Logger,
 		config:    config,
 		guard:     guard,
 		isRunning: true,
-		logger:    logger,
+		logger:    logger.With("node", node),
 		cLock:     exclusiveCheck,
+		info:      WatcherInfo{State: WatcherStateName(WatcherConnecting), TxCheck: "unknown"},
 	}
 }
 
@@ -56,28 +68,32 @@ func (w *Watcher) Start() {
 		case WatcherConnecting:
 			for w.isRunning {
 				w.CleanUp()
-				w.guard.ReportWatcher(w.node, WatcherConnecting)
+				w.reportState(WatcherConnecting)
 				// 1. create client
 				w.client = fastclient.NewFastClient(w.node, time.Duration(w.config.NewBlockTimeout)*time.Second)
+				w.client.SetObserver(w.observeRpc())
 				err := w.client.CheckConnection()
 				if err != nil {
-					w.logger.Error(fmt.Sprintf("[%s] Error in connecting: %s", w.node, err.Error()))
+					w.logger.Error("error in connecting", "err", err.Error())
+					w.reportError(err)
 					time.Sleep(time.Second * time.Duration(w.config.FallbackPause))
 					continue
 				}
 
 				// 2. all ok, change state
+				w.reportConnected()
 				w.state = WatcherQueryValidator
 				break
 			}
 
 		case WatcherQueryValidator:
 			{
-				w.guard.ReportWatcher(w.node, WatcherQueryValidator)
+				w.reportState(WatcherQueryValidator)
 				// query initial information from node: last height, validator set
 				_, err = w.queryValidatorSet()
 				if err != nil {
-					w.logger.Error(fmt.Sprintf("[%s] queryValidatorSet: %s", w.node, err.Error()))
+					w.logger.Error("initial query of validator set", "err", err.Error())
+					w.reportError(err)
 					w.state = WatcherConnecting
 				} else {
 					w.state = WatcherWatching
@@ -86,17 +102,19 @@ func (w *Watcher) Start() {
 			}
 
 		case WatcherWatching:
-			w.guard.ReportWatcher(w.node, WatcherWatching)
+			w.reportState(WatcherWatching)
 			for w.isRunning {
 				doBreak = false
 				err := w.querySignatures()
 				if err != nil {
-					w.logger.Error(fmt.Sprintf("[%s] querySignatures error: %s", w.node, err.Error()))
+					w.logger.Error("query of signatures", "err", err.Error())
+					w.reportError(err)
 					doBreak = true
 				}
 				block, err = w.queryValidatorSet()
 				if err != nil {
-					w.logger.Error(fmt.Sprintf("[%s] queryValidatorSet error: %s", w.node, err.Error()))
+					w.logger.Error("query of validator set", "err", err.Error())
+					w.reportError(err)
 					doBreak = true
 				}
 				if counter.increment(block) {
@@ -107,6 +125,7 @@ func (w *Watcher) Start() {
 					w.state = WatcherConnecting
 					break
 				}
+				w.reportPollOk()
 				// Decimal: >5 sec per block
 				time.Sleep(time.Second*5 + time.Millisecond*time.Duration(rand.Intn(500)))
 			}
@@ -114,6 +133,11 @@ func (w *Watcher) Start() {
 	}
 }
 
+// SetMetrics must be called before Start
+func (w *Watcher) SetMetrics(metrics *Metrics) {
+	w.metrics = metrics
+}
+
 func (w *Watcher) Stop() {
 	w.isRunning = false
 }
@@ -125,6 +149,7 @@ func (w *Watcher) CleanUp() {
 
 func (w *Watcher) SetTxData(txData []byte) {
 	w.txData = txData
+	w.txWasValid = false
 }
 
 func (w *Watcher) checkTxData() {
@@ -135,39 +160,27 @@ func (w *Watcher) checkTxData() {
 
 	res, err := w.client.CheckTx(w.txData)
 	if err != nil {
-		w.logger.Error(fmt.Sprintf("[%s] CheckTx error: %s", w.node, err.Error()))
+		w.logger.Error("CheckTx error", "err", err.Error())
+		w.reportTxCheck("error")
+		w.reportTxCheckError(err)
 		return
 	}
+	w.reportTxCheckOk()
 	if res.Code != 0 {
-		w.logger.Error(fmt.Sprintf("[%s] Check set_offline transaction: code=%d, codespace=%s, log=%s", w.node, res.Code, res.Codespace, res.Log))
+		w.logger.Error("check of set_offline transaction failed", "code", res.Code, "codespace", res.Codespace, "log", res.Log)
 		w.guard.ReportTxValidity(w.node, false)
+		reason, details := w.diagnoseTx(res)
+		w.reportTxCheck("invalid: " + TxReasonName(reason))
+		w.logger.Error("set_offline transaction is invalid", "reason", TxReasonName(reason), "details", details)
+		w.guard.ReportTxDiagnosis(w.node, reason, details)
 		return
 	}
-	w.logger.Info(fmt.Sprintf("[%s] Check set_offline transaction ok", w.node))
+	w.logger.Debug("check of set_offline transaction ok")
+	w.txWasValid = true
+	w.reportTxCheck("valid")
 	w.guard.ReportTxValidity(w.node, true)
 }
 
-func (w *Watcher) SendOffline() {
-	if w.txData == nil {
-		w.logger.Error(fmt.Sprintf("[%s] set_offline transaction is null", w.node))
-		return
-	}
-	if w.state != WatcherWatching {
-		w.logger.Error(fmt.Sprintf("[%s] Watcher not watching", w.node))
-		return
-	}
-	res, err := w.client.BroadcastTxSync(w.txData)
-	if err != nil {
-		w.logger.Error(fmt.Sprintf("[%s] BroadcastTxSync error: %s", w.node, err.Error()))
-		return
-	}
-	if res.Code != 0 {
-		w.logger.Error(fmt.Sprintf("[%s] BroadcastTxSync set_offline transaction: code=%d, codespace=%s, log=%s", w.node, res.Code, res.Codespace, res.Log))
-	}
-	w.txData = nil
-	w.logger.Info(fmt.Sprintf("[%s] BroadcastTxSync succesful", w.node))
-}
-
 // return true if block isn't outdated
 func (w *Watcher) SetLastValidatorHeight(height int64) bool {
 	if height <= w.lastValidatorHeight {
@@ -192,20 +205,21 @@ func (w *Watcher) queryValidatorSet() (int64, error) {
 		return 0, fmt.Errorf("call Validators(): %s", err.Error())
 	}
 	isNew := w.SetLastValidatorHeight(validatorSet.BlockHeight)
-	w.logger.Info(fmt.Sprintf("[%s] Retrieved set of validators for block %d", w.node, w.lastValidatorHeight))
+	w.reportHeights()
+	w.logger.Debug("retrieved set of validators", "height", w.lastValidatorHeight)
 
 	if isNew {
 		// Check if validato in set and has power
 		for _, v := range validatorSet.Validators {
 			if strings.EqualFold(v.Address, w.config.ValidatorAddress) {
-				w.logger.Info(fmt.Sprintf("[%s] validator in set: %s", w.node, v.Address))
+				w.logger.Debug("validator in set", "height", w.lastValidatorHeight, "power", v.VotingPower)
 				w.guard.ReportValidatorOnline(w.node, w.lastValidatorHeight, v.VotingPower > "0")
 				return w.lastValidatorHeight, nil
 			}
 		}
 		// validator not found
 		w.guard.ReportValidatorOnline(w.node, w.lastValidatorHeight, false)
-		w.logger.Info(fmt.Sprintf("[%s] validator not in set: %s", w.node, w.config.ValidatorAddress))
+		w.logger.Info("validator not in set", "height", w.lastValidatorHeight)
 	}
 
 	return w.lastValidatorHeight, nil
@@ -218,7 +232,9 @@ func (w *Watcher) querySignatures() error {
 		return fmt.Errorf("call Validators(): %s", err.Error())
 	}
 	isNew := w.SetLastSignatureHeight(signatures.Height)
-	w.logger.Info(fmt.Sprintf("[%s] Retrieved signatures for block %d", w.node, w.lastSignatureHeight))
+	w.reportHeights()
+	w.metrics.WatcherHeight(w.node, w.lastSignatureHeight)
+	w.logger.Debug("retrieved signatures", "height", w.lastSignatureHeight)
 
 	if isNew {
 		// Check if it is expected that block is signed by guarded validator's node
@@ -0,0 +1,128 @@
+package guard
+
+import (
+	"time"
+)
+
+// WatcherInfo is detailed state of watcher for /watchers, heights are last received from node
+type WatcherInfo struct {
+	Node                string    `json:"node"`
+	State               string    `json:"state"`
+	LastValidatorHeight int64     `json:"last_validator_height"`
+	LastSignatureHeight int64     `json:"last_signature_height"`
+	TxCheck             string    `json:"tx_check"` // valid, invalid: <reason>, error or unknown (not checked)
+	TxCheckTime         time.Time `json:"tx_check_time"`
+	LastError           string    `json:"last_error"`
+	LastErrorTime       time.Time `json:"last_error_time"`
+	Errors              int       `json:"errors"`
+	Reconnects          int       `json:"reconnects"` // successful connections after the first one
+	RpcRequests         int       `json:"rpc_requests"`
+	RpcLatencyMs        float64   `json:"rpc_latency_ms"`     // last request
+	RpcLatencyAvgMs     float64   `json:"rpc_latency_avg_ms"` // all requests since start
+
+	connected       bool
+	rpcLatencyTotal time.Duration
+}
+
+// Info returns copy of watcher details, it is safe to call from other goroutines
+func (w *Watcher) Info() WatcherInfo {
+	w.infoMu.Lock()
+	defer w.infoMu.Unlock()
+	info := w.info
+	info.Node = w.node
+	if info.RpcRequests > 0 {
+		info.RpcLatencyAvgMs = float64(info.rpcLatencyTotal.Microseconds()) / 1000 / float64(info.RpcRequests)
+	}
+	return info
+}
+
+func (w *Watcher) reportState(state WatcherState) {
+	w.infoMu.Lock()
+	w.info.State = WatcherStateName(state)
+	w.infoMu.Unlock()
+	w.guard.ReportWatcher(w.node, state)
+}
+
+func (w *Watcher) reportConnected() {
+	w.infoMu.Lock()
+	defer w.infoMu.Unlock()
+	if w.info.connected {
+		w.info.Reconnects++
+	}
+	w.info.connected = true
+}
+
+// reportError reports error of node connection or polling
+func (w *Watcher) reportError(err error) {
+	w.pollFailed = true
+	w.sendError(err)
+}
+
+// reportTxCheckError reports error of CheckTx request
+func (w *Watcher) reportTxCheckError(err error) {
+	w.txCheckFailed = true
+	w.sendError(err)
+}
+
+func (w *Watcher) sendError(err error) {
+	w.errorReported = true
+	w.infoMu.Lock()
+	w.info.LastError = err.Error()
+	w.info.LastErrorTime = time.Now()
+	w.info.Errors++
+	w.infoMu.Unlock()
+	w.guard.ReportWatcherError(w.node, err)
+	w.metrics.WatcherError(w.node)
+}
+
+func (w *Watcher) reportPollOk() {
+	w.pollFailed = false
+	w.reportOk()
+}
+
+func (w *Watcher) reportTxCheckOk() {
+	w.txCheckFailed = false
+	w.reportOk()
+}
+
+// reportOk resolves watcher_error alert once when all failed requests succeed again
+func (w *Watcher) reportOk() {
+	if !w.errorReported || w.pollFailed || w.txCheckFailed {
+		return
+	}
+	w.errorReported = false
+	w.guard.ReportWatcherOk(w.node)
+}
+
+func (w *Watcher) reportTxCheck(result string) {
+	w.infoMu.Lock()
+	defer w.infoMu.Unlock()
+	w.info.TxCheck = result
+	w.info.TxCheckTime = time.Now()
+}
+
+func (w *Watcher) reportHeights() {
+	w.infoMu.Lock()
+	defer w.infoMu.Unlock()
+	if w.lastValidatorHeight > w.info.LastValidatorHeight {
+		w.info.LastValidatorHeight = w.lastValidatorHeight
+	}
+	if w.lastSignatureHeight > w.info.LastSignatureHeight {
+		w.info.LastSignatureHeight = w.lastSignatureHeight
+	}
+}
+
+// observeRpc is fastclient observer: latency is kept for Info and passed to metrics
+func (w *Watcher) observeRpc() func(method string, duration time.Duration, err error) {
+	metricsObserver := w.metrics.RpcObserver(w.node)
+	return func(method string, duration time.Duration, err error) {
+		w.infoMu.Lock()
+		w.info.RpcRequests++
+		w.info.RpcLatencyMs = float64(duration.Microseconds()) / 1000
+		w.info.rpcLatencyTotal += duration
+		w.infoMu.Unlock()
+		if metricsObserver != nil {
+			metricsObserver(method, duration, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package guard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestWatcherInfo(t *testing.T) {
+	logger := tmlog.NewTMLogger(dummyWriter{})
+	gsm := NewGuardState(logger, Config{}, nil)
+	w := NewWatcher("http://a:26657", Config{}, gsm, logger, nil)
+	require.Equal(t, "connecting", w.Info().State)
+	require.Equal(t, "unknown", w.Info().TxCheck)
+
+	w.reportConnected()
+	w.reportError(errors.New("connection refused"))
+	w.reportConnected()
+	w.reportState(WatcherWatching)
+	w.SetLastSignatureHeight(10)
+	w.reportHeights()
+	w.CleanUp()
+	w.reportHeights()
+	observe := w.observeRpc()
+	observe("block", 10*time.Millisecond, nil)
+	observe("validators", 30*time.Millisecond, nil)
+	w.reportTxCheck("valid")
+
+	info := w.Info()
+	require.Equal(t, "http://a:26657", info.Node)
+	require.Equal(t, "watching", info.State)
+	require.Equal(t, int64(10), info.LastSignatureHeight)
+	require.Equal(t, "connection refused", info.LastError)
+	require.Equal(t, 1, info.Errors)
+	require.Equal(t, 1, info.Reconnects)
+	require.Equal(t, 2, info.RpcRequests)
+	require.Equal(t, 30.0, info.RpcLatencyMs)
+	require.Equal(t, 20.0, info.RpcLatencyAvgMs)
+	require.Equal(t, "valid", info.TxCheck)
+	require.Len(t, gsm.eventChannel, 0) // state machine is not running
+}
+
+func TestWatcherErrorResolved(t *testing.T) {
+	logger := tmlog.NewTMLogger(dummyWriter{})
+	rn := &recordNotifier{}
+	gsm := NewGuardState(logger, Config{}, nil)
+	gsm.SetNotifier(rn)
+	gsm.isRunning = true
+	w := NewWatcher("a", Config{}, gsm, logger, nil)
+
+	// CheckTx fails while watcher is watching: successful polls don't resolve alert
+	w.reportPollOk()
+	w.reportTxCheckError(errors.New("timeout"))
+	w.reportPollOk()
+	w.reportTxCheckOk()
+	w.reportPollOk()
+	require.Len(t, gsm.eventChannel, 2)
+	for len(gsm.eventChannel) > 0 {
+		gsm.ProcessEvent(<-gsm.eventChannel)
+	}
+	var alerts []Notification
+	for _, n := range rn.notifications {
+		if n.Key == NotifyWatcherError+":a" {
+			alerts = append(alerts, n)
+		}
+	}
+	require.Len(t, alerts, 2)
+	require.True(t, alerts[1].Resolved)
+	events := gsm.Events(HistoryFilter{})
+	require.Equal(t, HistoryWatcherOk, events[len(events)-1].Type)
+}
@@ -0,0 +1,115 @@
+package guard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMissedBlocksWarnings is used if MISSED_BLOCKS_WARNINGS is empty
+const DefaultMissedBlocksWarnings = "50"
+
+// ParseWarnings parses warning thresholds: percents of missed blocks limit separated by ',', 'none' disables warnings
+func ParseWarnings(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = DefaultMissedBlocksWarnings
+	}
+	if s == "none" {
+		return nil, nil
+	}
+	var warnings []int
+	for _, part := range strings.Split(s, ",") {
+		percent, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "%")))
+		if err != nil || percent <= 0 || percent >= 100 {
+			return nil, fmt.Errorf("invalid warning threshold '%s', expected percent from 1 to 99", strings.TrimSpace(part))
+		}
+		warnings = append(warnings, percent)
+	}
+	sort.Ints(warnings)
+	return warnings, nil
+}
+
+// WarningBlocks returns count of missed blocks for warning threshold (rounded up, at least 1)
+func WarningBlocks(limit int, percent int) int {
+	blocks := (limit*percent + 99) / 100
+	if blocks < 1 {
+		blocks = 1
+	}
+	return blocks
+}
+
+type BlockSign = string
+
+const (
+	BlockSigned  BlockSign = "signed"
+	BlockMissed  BlockSign = "missed"
+	BlockUnknown BlockSign = "unknown" // validator was offline or block is not received
+)
+
+type WindowBlock struct {
+	Height int64     `json:"height"`
+	Sign   BlockSign `json:"sign"`
+}
+
+// WindowStatus is state of sliding window of signed blocks
+type WindowStatus struct {
+	Height     int64         `json:"height"`
+	Size       int           `json:"size"`
+	Missed     int           `json:"missed"`
+	Limit      int           `json:"limit"`
+	Streak     int           `json:"miss_streak"`        // consecutive missed blocks up to current height
+	LastSigned int64         `json:"last_signed_height"` // 0 if validator didn't sign since start of guard
+	Blocks     []WindowBlock `json:"blocks"`             // from oldest to newest
+}
+
+// SignWindow returns copy of sign window for current height
+func (sm *GuardStateMachine) SignWindow() WindowStatus {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	ws := WindowStatus{
+		Height:     sm.currentHeight,
+		Size:       len(sm.signWindow),
+		Limit:      sm.config.MissedBlocksLimit,
+		LastSigned: sm.lastSigned,
+		Blocks:     []WindowBlock{},
+	}
+	for h := sm.currentHeight - int64(len(sm.signWindow)) + 1; h <= sm.currentHeight; h++ {
+		if h <= 0 {
+			continue
+		}
+		idx := int(h) % len(sm.signWindow)
+		block := WindowBlock{Height: h, Sign: BlockUnknown}
+		if sm.signKnown[idx] {
+			block.Sign = BlockSigned
+			if !sm.signWindow[idx] {
+				block.Sign = BlockMissed
+				ws.Missed++
+			}
+		}
+		if block.Sign == BlockMissed {
+			ws.Streak++
+		} else {
+			ws.Streak = 0
+		}
+		ws.Blocks = append(ws.Blocks, block)
+	}
+	return ws
+}
+
+// Marks shows window as line of marks: '+' signed, '-' missed, '?' unknown
+func (ws WindowStatus) Marks() string {
+	var marks strings.Builder
+	for _, b := range ws.Blocks {
+		switch b.Sign {
+		case BlockSigned:
+			marks.WriteByte('+')
+		case BlockMissed:
+			marks.WriteByte('-')
+		default:
+			marks.WriteByte('?')
+		}
+	}
+	return marks.String()
+}
@@ -0,0 +1,149 @@
+package keys
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptoCodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethermintCodec "github.com/evmos/ethermint/crypto/codec"
+	"github.com/evmos/ethermint/crypto/ethsecp256k1"
+	ethermintHd "github.com/evmos/ethermint/crypto/hd"
+	ethermint "github.com/evmos/ethermint/types"
+	"golang.org/x/term"
+
+	"bitbucket.org/decimalteam/dsc-guard/txdata"
+)
+
+// Sources of signing key
+const (
+	SourcePrompt  = "prompt"  // mnemonic from hidden interactive prompt
+	SourceStdin   = "stdin"   // mnemonic from first line of stdin
+	SourceKeyring = "keyring" // key from Cosmos SDK keyring
+	SourceEnv     = "env"     // plaintext MNEMONIC from .env file, insecure
+)
+
+var DefaultKeyringDir = os.ExpandEnv("$HOME/.decimal/daemon")
+
+var ErrInsecureEnv = errors.New("plaintext MNEMONIC from .env is insecure, use keyring, prompt or stdin (or explicit insecure flag)")
+
+type Options struct {
+	Source         string
+	KeyringBackend string // file, os, test
+	KeyringDir     string
+	KeyName        string
+	EnvMnemonic    string // MNEMONIC value from .env
+	AllowInsecure  bool   // allow SourceEnv
+	Input          *bufio.Reader
+}
+
+// Load returns signer from configured source
+func Load(opts Options) (txdata.Signer, error) {
+	if opts.Input == nil {
+		opts.Input = bufio.NewReader(os.Stdin)
+	}
+	switch opts.Source {
+	case SourcePrompt:
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("stdin is not a terminal, use key source '%s'", SourceStdin)
+		}
+		fmt.Fprint(os.Stderr, "Enter mnemonic: ")
+		bz, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		return FromMnemonic(string(bz))
+	case SourceStdin:
+		line, err := opts.Input.ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("read mnemonic from stdin: %s", err.Error())
+		}
+		return FromMnemonic(line)
+	case SourceKeyring:
+		return fromKeyring(opts)
+	case SourceEnv:
+		if !opts.AllowInsecure {
+			return nil, ErrInsecureEnv
+		}
+		return FromMnemonic(opts.EnvMnemonic)
+	}
+	return nil, fmt.Errorf("unknown key source '%s'", opts.Source)
+}
+
+// FromMnemonic derives Decimal (ethereum-like) key from mnemonic
+func FromMnemonic(mnemonic string) (txdata.Signer, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if mnemonic == "" {
+		return nil, errors.New("mnemonic is empty")
+	}
+	bz, err := ethermintHd.EthSecp256k1.Derive()(mnemonic, "", ethermint.BIP44HDPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ethsecp256k1.PrivKey{Key: bz}, nil
+}
+
+type keyringSigner struct {
+	kr     keyring.Keyring
+	uid    string
+	pubKey cryptoTypes.PubKey
+}
+
+func (ks *keyringSigner) PubKey() cryptoTypes.PubKey {
+	return ks.pubKey
+}
+
+func (ks *keyringSigner) Sign(msg []byte) ([]byte, error) {
+	sig, _, err := ks.kr.Sign(ks.uid, msg)
+	return sig, err
+}
+
+func fromKeyring(opts Options) (txdata.Signer, error) {
+	switch opts.KeyringBackend {
+	case keyring.BackendFile, keyring.BackendOS, keyring.BackendTest:
+	default:
+		return nil, fmt.Errorf("unsupported keyring backend '%s'", opts.KeyringBackend)
+	}
+	if opts.KeyName == "" {
+		return nil, errors.New("key name is required for keyring")
+	}
+	dir := opts.KeyringDir
+	if dir == "" {
+		dir = DefaultKeyringDir
+	}
+	registry := codecTypes.NewInterfaceRegistry()
+	cryptoCodec.RegisterInterfaces(registry)
+	ethermintCodec.RegisterInterfaces(registry)
+	kr, err := keyring.New(sdk.KeyringServiceName(), opts.KeyringBackend, dir, opts.Input,
+		codec.NewProtoCodec(registry), ethermintHd.EthSecp256k1Option())
+	if err != nil {
+		return nil, err
+	}
+	record, err := kr.Key(opts.KeyName)
+	if err != nil {
+		return nil, fmt.Errorf("key '%s': %s", opts.KeyName, err.Error())
+	}
+	pubKey, err := record.GetPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return &keyringSigner{kr: kr, uid: opts.KeyName, pubKey: pubKey}, nil
+}
+
+// BindFlags registers flags to choose signing key
+func BindFlags(fs *flag.FlagSet, opts *Options) {
+	fs.StringVar(&opts.Source, "key-source", SourcePrompt, "source of signing key: prompt, stdin, keyring or env (insecure)")
+	fs.StringVar(&opts.KeyringBackend, "keyring-backend", keyring.BackendFile, "keyring backend: file, os or test")
+	fs.StringVar(&opts.KeyringDir, "keyring-dir", DefaultKeyringDir, "keyring directory")
+	fs.StringVar(&opts.KeyName, "key", "", "key name in keyring")
+	fs.BoolVar(&opts.AllowInsecure, "insecure-env-mnemonic", false, "allow plaintext MNEMONIC from .env")
+}
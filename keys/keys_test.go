@@ -0,0 +1,52 @@
+package keys
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptoCodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethermintCodec "github.com/evmos/ethermint/crypto/codec"
+	ethermintHd "github.com/evmos/ethermint/crypto/hd"
+	ethermint "github.com/evmos/ethermint/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "bulb raw claw magnet romance jaguar life cluster solve random laptop salmon pottery subject country aware actual hope wedding hawk amused cage secret network"
+
+func TestLoad(t *testing.T) {
+	expected, err := FromMnemonic(testMnemonic)
+	require.NoError(t, err)
+
+	// stdin
+	signer, err := Load(Options{Source: SourceStdin, Input: bufio.NewReader(strings.NewReader(testMnemonic + "\n"))})
+	require.NoError(t, err)
+	require.Equal(t, expected.PubKey().Address(), signer.PubKey().Address())
+
+	// .env
+	_, err = Load(Options{Source: SourceEnv, EnvMnemonic: testMnemonic})
+	require.ErrorIs(t, err, ErrInsecureEnv)
+	signer, err = Load(Options{Source: SourceEnv, EnvMnemonic: testMnemonic, AllowInsecure: true})
+	require.NoError(t, err)
+	require.Equal(t, expected.PubKey().Address(), signer.PubKey().Address())
+
+	// keyring
+	dir := t.TempDir()
+	registry := codecTypes.NewInterfaceRegistry()
+	cryptoCodec.RegisterInterfaces(registry)
+	ethermintCodec.RegisterInterfaces(registry)
+	kr, err := keyring.New(sdk.KeyringServiceName(), keyring.BackendTest, dir, nil, codec.NewProtoCodec(registry), ethermintHd.EthSecp256k1Option())
+	require.NoError(t, err)
+	_, err = kr.NewAccount("operator", testMnemonic, "", ethermint.BIP44HDPath, ethermintHd.EthSecp256k1)
+	require.NoError(t, err)
+	signer, err = Load(Options{Source: SourceKeyring, KeyringBackend: keyring.BackendTest, KeyringDir: dir, KeyName: "operator"})
+	require.NoError(t, err)
+	require.Equal(t, expected.PubKey().Address(), signer.PubKey().Address())
+	sig, err := signer.Sign([]byte("message"))
+	require.NoError(t, err)
+	require.True(t, signer.PubKey().VerifySignature([]byte("message"), sig))
+}
@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// AlertmanagerRepeat is interval to re-push firing alerts, Alertmanager resolves alerts which are not updated
+const AlertmanagerRepeat = time.Minute
+
+// Alertmanager pushes alerts to Prometheus Alertmanager API v2
+type Alertmanager struct {
+	url          string
+	generatorUrl string
+	retries      int
+	retryPause   time.Duration
+	client       *http.Client
+
+	mu     sync.Mutex
+	firing map[string]amAlert // by notification key
+}
+
+type amAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+func NewAlertmanager(url, generatorUrl string) *Alertmanager {
+	return &Alertmanager{
+		url:          strings.TrimRight(url, "/") + "/api/v2/alerts",
+		generatorUrl: generatorUrl,
+		retries:      DefaultWebhookRetries,
+		retryPause:   time.Second,
+		client:       &http.Client{Timeout: DefaultWebhookTimeout},
+		firing:       make(map[string]amAlert),
+	}
+}
+
+func (am *Alertmanager) Name() string {
+	return "alertmanager"
+}
+
+// alertName converts notification type to alert name: tx_invalid -> DscGuardTxInvalid
+func alertName(typ guard.NotificationType) string {
+	name := "DscGuard"
+	for _, part := range strings.Split(typ, "_") {
+		if part > "" {
+			name += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return name
+}
+
+// Send pushes warning and critical notifications; alert with key is firing until resolved,
+// notification without key is pushed as alert for AlertmanagerRepeat.
+// Severity is label of alert: on change of severity previous alert is resolved and new one is pushed.
+func (am *Alertmanager) Send(n guard.Notification) error {
+	if n.Severity == guard.SeverityInfo && !n.Resolved {
+		return nil
+	}
+	am.mu.Lock()
+	alert, open := am.firing[n.Key]
+	var alerts []amAlert
+	switch {
+	case n.Resolved:
+		if !open {
+			am.mu.Unlock()
+			return nil
+		}
+		delete(am.firing, n.Key)
+		alert.EndsAt = n.Time
+	case open && alert.Labels["severity"] != n.Severity:
+		alert.EndsAt = n.Time
+		alerts = append(alerts, alert)
+		alert = am.alert(n)
+		am.firing[n.Key] = alert
+	case open:
+		// labels must stay the same to update the same alert
+		alert.Annotations = am.annotations(n)
+		alert.EndsAt = n.Time.Add(3 * AlertmanagerRepeat)
+		am.firing[n.Key] = alert
+	default:
+		alert = am.alert(n)
+		if n.Key > "" {
+			am.firing[n.Key] = alert
+		}
+	}
+	am.mu.Unlock()
+	return am.push(append(alerts, alert))
+}
+
+func (am *Alertmanager) alert(n guard.Notification) amAlert {
+	labels := map[string]string{
+		"alertname": alertName(n.Type),
+		"severity":  n.Severity,
+		"validator": n.Validator,
+		"state":     n.State,
+		"job":       "dsc-guard",
+	}
+	if n.Key > "" {
+		labels["key"] = n.Key
+	}
+	if node, ok := n.Details["node"].(string); ok {
+		labels["node"] = node
+	}
+	ttl := AlertmanagerRepeat
+	if n.Key > "" {
+		ttl = 3 * AlertmanagerRepeat
+	}
+	return amAlert{
+		Labels:       labels,
+		Annotations:  am.annotations(n),
+		StartsAt:     n.Time,
+		EndsAt:       n.Time.Add(ttl),
+		GeneratorURL: am.generatorUrl,
+	}
+}
+
+func (am *Alertmanager) annotations(n guard.Notification) map[string]string {
+	return map[string]string{
+		"summary":     n.Message,
+		"description": strings.TrimRight(formatMap(n.Details), "\n"),
+		"height":      fmt.Sprint(n.Height),
+	}
+}
+
+// Run re-pushes firing alerts, otherwise Alertmanager resolves them after endsAt
+func (am *Alertmanager) Run(ctx context.Context) {
+	tick := time.NewTicker(AlertmanagerRepeat)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-tick.C:
+			am.mu.Lock()
+			var alerts []amAlert
+			for key, alert := range am.firing {
+				alert.EndsAt = now.Add(3 * AlertmanagerRepeat)
+				am.firing[key] = alert
+				alerts = append(alerts, alert)
+			}
+			am.mu.Unlock()
+			if len(alerts) > 0 {
+				am.push(alerts)
+			}
+		}
+	}
+}
+
+func (am *Alertmanager) push(alerts []amAlert) error {
+	bz, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	return retry(am.retries, am.retryPause, func() error {
+		resp, err := am.client.Post(am.url, "application/json", bytes.NewReader(bz))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err = fmt.Errorf("http code %d: %s", resp.StatusCode, body)
+		if resp.StatusCode == http.StatusBadRequest {
+			return permanentError{err}
+		}
+		return err
+	})
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+func TestAlertmanager(t *testing.T) {
+	pushed := make(chan []amAlert, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/alerts", r.URL.Path)
+		var alerts []amAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alerts))
+		pushed <- alerts
+	}))
+	defer srv.Close()
+
+	am := NewAlertmanager(srv.URL+"/", "http://guard/")
+	start := time.Now().UTC().Truncate(time.Second)
+	firing := guard.Notification{
+		Type:      guard.NotifyWatcherDown,
+		Key:       "watcher_down:http://node:26657",
+		Severity:  guard.SeverityWarning,
+		Validator: "AA",
+		State:     "watching",
+		Message:   "watcher is disconnected",
+		Details:   map[string]interface{}{"node": "http://node:26657"},
+		Time:      start,
+	}
+	require.NoError(t, am.Send(firing))
+	alert := (<-pushed)[0]
+	require.Equal(t, "DscGuardWatcherDown", alert.Labels["alertname"])
+	require.Equal(t, "http://node:26657", alert.Labels["node"])
+	require.Equal(t, "watching", alert.Labels["state"])
+	require.Equal(t, start.Add(3*AlertmanagerRepeat), alert.EndsAt)
+
+	// severity is label: previous alert is resolved, new one is firing
+	raised := firing
+	raised.Severity = guard.SeverityCritical
+	raised.Time = start.Add(30 * time.Second)
+	require.NoError(t, am.Send(raised))
+	changed := <-pushed
+	require.Len(t, changed, 2)
+	require.Equal(t, alert.Labels, changed[0].Labels)
+	require.Equal(t, raised.Time, changed[0].EndsAt)
+	require.Equal(t, "critical", changed[1].Labels["severity"])
+	require.Equal(t, raised.Time, changed[1].StartsAt)
+	alert = changed[1]
+
+	// resolved alert has the same labels even if state is changed
+	resolved := firing
+	resolved.Resolved = true
+	resolved.State = "connecting"
+	resolved.Time = start.Add(time.Minute)
+	require.NoError(t, am.Send(resolved))
+	ended := (<-pushed)[0]
+	require.Equal(t, alert.Labels, ended.Labels)
+	require.Equal(t, raised.Time, ended.StartsAt)
+	require.Equal(t, start.Add(time.Minute), ended.EndsAt)
+	require.Empty(t, am.firing)
+
+	// info is not pushed
+	require.NoError(t, am.Send(guard.Notification{Type: guard.NotifyStateChanged, Severity: guard.SeverityInfo}))
+	require.Len(t, pushed, 0)
+}
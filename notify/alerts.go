@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"sync"
+	"time"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+const AlertCheckInterval = 10 * time.Second
+
+var severityRank = map[guard.Severity]int{
+	guard.SeverityInfo:     0,
+	guard.SeverityWarning:  1,
+	guard.SeverityCritical: 2,
+}
+
+// AlertManager implements guard.Notifier on top of dispatchers:
+// - alerts (notifications with Key) are sent once until resolved or severity is raised
+// - open alerts are repeated every renotify interval
+// - critical alert open longer than escalateAfter is sent to escalation dispatcher
+// - resolved notice is sent only for open alerts
+type AlertManager struct {
+	primary       *Dispatcher
+	escalation    *Dispatcher
+	renotify      time.Duration // 0 - don't repeat
+	escalateAfter time.Duration // 0 - don't escalate
+	alerts        map[string]*openAlert
+	logger        tmlog.Logger
+	mu            sync.Mutex
+	stop          chan struct{}
+	wg            sync.WaitGroup
+}
+
+type openAlert struct {
+	n         guard.Notification // last occurrence
+	since     time.Time
+	lastSent  time.Time
+	escalated bool
+}
+
+func NewAlertManager(primary, escalation *Dispatcher, renotify, escalateAfter time.Duration, logger tmlog.Logger) *AlertManager {
+	if escalation == nil {
+		escalation = NewDispatcher(logger)
+	}
+	return &AlertManager{
+		primary:       primary,
+		escalation:    escalation,
+		renotify:      renotify,
+		escalateAfter: escalateAfter,
+		alerts:        make(map[string]*openAlert),
+		logger:        logger,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Senders returns count of configured senders
+func (am *AlertManager) Senders() int {
+	return am.primary.Senders() + am.escalation.Senders()
+}
+
+func (am *AlertManager) Start() {
+	am.primary.Start()
+	am.escalation.Start()
+	am.wg.Add(1)
+	go func() {
+		defer am.wg.Done()
+		tick := time.NewTicker(AlertCheckInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-am.stop:
+				return
+			case now := <-tick.C:
+				am.check(now)
+			}
+		}
+	}()
+}
+
+func (am *AlertManager) Stop() {
+	close(am.stop)
+	am.wg.Wait()
+	am.primary.Stop()
+	am.escalation.Stop()
+}
+
+func (am *AlertManager) Notify(n guard.Notification) {
+	if n.Key == "" {
+		am.primary.Notify(n)
+		return
+	}
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	a, open := am.alerts[n.Key]
+	if n.Resolved {
+		if !open {
+			return
+		}
+		delete(am.alerts, n.Key)
+		am.primary.Notify(n)
+		if a.escalated {
+			am.escalation.Notify(n)
+		}
+		return
+	}
+	if !open {
+		am.alerts[n.Key] = &openAlert{n: n, since: n.Time, lastSent: n.Time}
+		am.primary.Notify(n)
+		return
+	}
+	raised := severityRank[n.Severity] > severityRank[a.n.Severity]
+	a.n = n
+	if raised {
+		a.lastSent = n.Time
+		am.primary.Notify(n)
+	}
+}
+
+// check repeats and escalates open alerts
+func (am *AlertManager) check(now time.Time) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	for _, a := range am.alerts {
+		if am.renotify > 0 && now.Sub(a.lastSent) >= am.renotify {
+			a.lastSent = now
+			am.primary.Repeat(withOpenSince(a.n, a.since, ""))
+		}
+		if am.escalateAfter > 0 && am.escalation.Senders() > 0 && !a.escalated &&
+			a.n.Severity == guard.SeverityCritical && now.Sub(a.since) >= am.escalateAfter {
+			a.escalated = true
+			am.logger.Info("alert is escalated", "key", a.n.Key)
+			am.escalation.Notify(withOpenSince(a.n, a.since, "escalated: "))
+		}
+	}
+}
+
+// withOpenSince returns copy of notification with time of first occurrence
+func withOpenSince(n guard.Notification, since time.Time, prefix string) guard.Notification {
+	details := make(map[string]interface{}, len(n.Details)+1)
+	for k, v := range n.Details {
+		details[k] = v
+	}
+	details["open_since"] = since.UTC().Format(time.RFC3339)
+	n.Details = details
+	n.Message = prefix + n.Message
+	return n
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+type recordSender struct {
+	name string
+	mu   sync.Mutex
+	sent []guard.Notification
+}
+
+func (rs *recordSender) Name() string {
+	return rs.name
+}
+
+func (rs *recordSender) Send(n guard.Notification) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.sent = append(rs.sent, n)
+	return nil
+}
+
+func (rs *recordSender) messages() []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	var messages []string
+	for _, n := range rs.sent {
+		messages = append(messages, n.Message)
+	}
+	return messages
+}
+
+func TestAlertManager(t *testing.T) {
+	logger := tmlog.NewTMLogger(dummyWriter{})
+	primary := &recordSender{name: "primary"}
+	escalation := &recordSender{name: "escalation"}
+	am := NewAlertManager(NewDispatcher(logger, primary), NewDispatcher(logger, escalation), time.Hour, 10*time.Minute, logger)
+	am.primary.Start()
+	am.escalation.Start()
+
+	start := time.Now()
+	alert := func(severity guard.Severity, at time.Duration) guard.Notification {
+		return guard.Notification{Key: "tx_invalid:sequence_used", Severity: severity, Message: "invalid " + severity, Time: start.Add(at)}
+	}
+	am.Notify(alert(guard.SeverityWarning, 0))
+	am.Notify(alert(guard.SeverityWarning, time.Minute)) // duplicate
+	am.Notify(alert(guard.SeverityCritical, 2*time.Minute))
+	am.Notify(alert(guard.SeverityCritical, 3*time.Minute)) // duplicate
+	am.Notify(guard.Notification{Type: guard.NotifyStateChanged, Message: "state", Time: start})
+	am.check(start.Add(5 * time.Minute))
+	am.check(start.Add(11 * time.Minute))
+	am.check(start.Add(12 * time.Minute)) // escalated once
+	am.check(start.Add(2*time.Minute + time.Hour))
+	am.Notify(guard.Notification{Key: "tx_invalid:sequence_used", Resolved: true, Message: "resolved", Time: start.Add(2 * time.Hour)})
+	am.Notify(guard.Notification{Key: "tx_invalid:sequence_used", Resolved: true, Message: "resolved", Time: start.Add(2 * time.Hour)})
+	am.primary.Stop()
+	am.escalation.Stop()
+
+	require.Equal(t, []string{"invalid warning", "invalid critical", "state", "invalid critical", "resolved"}, primary.messages())
+	require.Equal(t, []string{"escalated: invalid critical", "resolved"}, escalation.messages())
+	require.Equal(t, start.UTC().Format(time.RFC3339), escalation.sent[0].Details["open_since"])
+}
+
+func TestAlertManagerHooksNotRepeated(t *testing.T) {
+	logger := tmlog.NewTMLogger(dummyWriter{})
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	hooks := NewHooks([]Hook{{Event: "*", Command: "echo $GUARD_MESSAGE >> " + out}}, time.Second, 1, logger)
+	other := &recordSender{name: "other"}
+	am := NewAlertManager(NewDispatcher(logger, hooks, other), nil, time.Minute, 0, logger)
+	am.primary.Start()
+
+	start := time.Now()
+	am.Notify(guard.Notification{Key: "watchers_down", Severity: guard.SeverityCritical, Message: "down", Time: start})
+	am.check(start.Add(time.Minute))
+	am.check(start.Add(2 * time.Minute))
+	am.Notify(guard.Notification{Key: "watchers_down", Resolved: true, Message: "up", Time: start.Add(3 * time.Minute)})
+	am.primary.Stop()
+
+	require.Equal(t, []string{"down", "down", "down", "up"}, other.messages())
+	bz, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "down\n", string(bz))
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// Security of SMTP connection
+const (
+	SmtpStartTLS = "starttls" // plain connection upgraded by STARTTLS, usually port 587
+	SmtpTLS      = "tls"      // implicit TLS, usually port 465
+	SmtpNone     = "none"     // unencrypted, only for local relay
+)
+
+// Email sends critical notifications through SMTP server
+type Email struct {
+	addr       string // host:port
+	host       string
+	security   string
+	username   string
+	password   string
+	from       string
+	to         []string
+	source     StatusSource
+	retries    int
+	retryPause time.Duration
+	timeout    time.Duration
+}
+
+func NewEmail(host string, port int, security, username, password, from string, to []string, source StatusSource) (*Email, error) {
+	switch security {
+	case "":
+		security = SmtpStartTLS
+	case SmtpStartTLS, SmtpTLS, SmtpNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP security '%s'", security)
+	}
+	if port == 0 {
+		port = 587
+		if security == SmtpTLS {
+			port = 465
+		}
+	}
+	if from == "" || len(to) == 0 {
+		return nil, errors.New("sender and recipients are required")
+	}
+	return &Email{
+		addr:       net.JoinHostPort(host, fmt.Sprint(port)),
+		host:       host,
+		security:   security,
+		username:   username,
+		password:   password,
+		from:       from,
+		to:         to,
+		source:     source,
+		retries:    DefaultWebhookRetries,
+		retryPause: time.Second,
+		timeout:    DefaultWebhookTimeout,
+	}, nil
+}
+
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Send delivers only critical notifications and digests
+func (e *Email) Send(n guard.Notification) error {
+	if !reportable(n, guard.SeverityCritical) {
+		return nil
+	}
+	msg := e.message(n)
+	return retry(e.retries, e.retryPause, func() error {
+		return e.send(msg)
+	})
+}
+
+func (e *Email) message(n guard.Notification) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", e.from)
+	fmt.Fprintf(&sb, "To: %s\r\n", strings.Join(e.to, ", "))
+	status := strings.ToUpper(n.Severity)
+	if n.Resolved {
+		status = "RESOLVED"
+	}
+	fmt.Fprintf(&sb, "Subject: [dsc-guard] %s: %s\r\n", status, n.Message)
+	fmt.Fprintf(&sb, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	body := formatText(n) + "\n\nGuard status:\n" + formatStatus(e.source.Status()) + "\nSign history (newest first):\n" + formatHistory(e.source.SignWindow())
+	sb.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(sb.String())
+}
+
+// formatHistory returns 'height: sign' lines from newest block
+func formatHistory(ws guard.WindowStatus) string {
+	var sb strings.Builder
+	for i := len(ws.Blocks) - 1; i >= 0; i-- {
+		fmt.Fprintf(&sb, "%d: %s\n", ws.Blocks[i].Height, ws.Blocks[i].Sign)
+	}
+	return sb.String()
+}
+
+func (e *Email) send(msg []byte) error {
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: e.timeout}
+	if e.security == SmtpTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", e.addr, &tls.Config{ServerName: e.host})
+	} else {
+		conn, err = dialer.Dial("tcp", e.addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(e.timeout))
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if e.security == SmtpStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return permanentError{errors.New("SMTP server doesn't support STARTTLS")}
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if e.username > "" {
+		if err := c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return permanentError{err}
+		}
+	}
+	if err := c.Mail(e.from); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// smtpSink accepts one message and returns recipients and data
+func smtpSink(t *testing.T) (string, chan []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	result := make(chan []string, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 sink")
+		var got []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 sink")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				got = append(got, strings.TrimSpace(line[len("RCPT TO:"):]))
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go")
+				var data strings.Builder
+				for {
+					l, _ := r.ReadString('\n')
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				got = append(got, data.String())
+				reply("250 ok")
+			case cmd == "QUIT":
+				reply("221 bye")
+				result <- got
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), result
+}
+
+func TestEmail(t *testing.T) {
+	addr, result := smtpSink(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+	e, err := NewEmail(host, portNum, SmtpNone, "", "", "guard@example.com", []string{"a@example.com", "b@example.com"}, stubSource{})
+	require.NoError(t, err)
+
+	// only critical notifications are sent
+	require.NoError(t, e.Send(guard.Notification{Severity: guard.SeverityWarning}))
+	require.NoError(t, e.Send(guard.Notification{
+		Type:     guard.NotifyWatchersDown,
+		Severity: guard.SeverityCritical,
+		Message:  "all watchers are disconnected from nodes",
+		Time:     time.Now(),
+	}))
+	got := <-result
+	require.Equal(t, []string{"<a@example.com>", "<b@example.com>"}, got[:2])
+	require.Contains(t, got[2], "Subject: [dsc-guard] CRITICAL: all watchers are disconnected from nodes")
+	require.Contains(t, got[2], "transaction_status: valid")
+	require.Contains(t, got[2], "100: signed\r\n99: missed\r\n98: unknown")
+
+	_, err = NewEmail(host, portNum, "ssl", "", "", "guard@example.com", []string{"a@example.com"}, stubSource{})
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+// StatusSource gives data for status commands and reports (implemented by guard.GuardStateMachine)
+type StatusSource interface {
+	Status() guard.GuardStatus
+	SignWindow() guard.WindowStatus
+}
+
+// formatText returns plain text of notification for messengers and email
+func formatText(n guard.Notification) string {
+	var sb strings.Builder
+	if n.Resolved {
+		fmt.Fprintf(&sb, "[RESOLVED] %s\n", n.Message)
+	} else {
+		fmt.Fprintf(&sb, "[%s] %s\n", strings.ToUpper(n.Severity), n.Message)
+	}
+	fmt.Fprintf(&sb, "validator: %s\nheight: %d\nstate: %s\n", n.Validator, n.Height, n.State)
+	sb.WriteString(formatMap(n.Details))
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatMap returns 'key: value' lines sorted by key, complex values are shown as json
+func formatMap(m map[string]interface{}) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case string, bool, int, int64, uint64, float64:
+			fmt.Fprintf(&sb, "%s: %v\n", k, v)
+		default:
+			bz, _ := json.Marshal(v)
+			fmt.Fprintf(&sb, "%s: %s\n", k, bz)
+		}
+	}
+	return sb.String()
+}
+
+// formatStatus returns 'key: value' lines of status, sign window is shown by formatWindow
+func formatStatus(s guard.GuardStatus) string {
+	return formatMap(map[string]interface{}{
+		"state":                      s.State,
+		"critical":                   s.Critical,
+		"validator_online":           s.ValidatorOnline,
+		"transaction_status":         s.TransactionStatus,
+		"transaction_invalid_reason": s.TransactionInvalidReason,
+		"transaction_error":          s.TransactionError,
+		"watchers_count":             s.WatchersCount,
+		"watchers_watching":          s.WatchersWatching,
+		"current_height":             s.CurrentHeight,
+		"grace_period_until":         s.GracePeriodUntil,
+		"missed_blocks":              s.MissedBlocks,
+		"miss_streak":                s.Window.Streak,
+		"last_signed_height":         s.Window.LastSigned,
+		"degraded":                   s.Degraded,
+		"warning_threshold":          s.WarningThreshold,
+	})
+}
+
+// formatWindow shows sign window marks and heights of missed blocks
+func formatWindow(ws guard.WindowStatus) string {
+	var missed []string
+	for _, b := range ws.Blocks {
+		if b.Sign == guard.BlockMissed {
+			missed = append(missed, fmt.Sprint(b.Height))
+		}
+	}
+	text := fmt.Sprintf("height %d, missed %d of %d blocks (limit %d), %d in a row, last signed %d\n%s",
+		ws.Height, ws.Missed, ws.Size, ws.Limit, ws.Streak, ws.LastSigned, ws.Marks())
+	if len(missed) > 0 {
+		text += "\nmissed: " + strings.Join(missed, ", ")
+	}
+	return text
+}
@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+const (
+	DefaultHookTimeout     = 30 * time.Second
+	DefaultHookConcurrency = 2
+	maxHookOutput          = 4096 // bytes of hook output written to log
+)
+
+// HookResolvedSuffix is added to event of hook which runs when alert is resolved: missed_blocks:50:resolved
+const HookResolvedSuffix = ":resolved"
+
+// Hook runs command for notifications matching event: notification type, alert key or '*'.
+// Hook runs either on alert or on its resolve notice (Resolved).
+type Hook struct {
+	Event    string
+	Resolved bool
+	Command  string
+}
+
+// ParseHooks parses hooks in form 'event=command; event2:resolved=command2'
+func ParseHooks(s string) ([]Hook, error) {
+	var hooks []Hook
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid hook '%s', expected 'event=command'", strings.TrimSpace(part))
+		}
+		event := strings.TrimSpace(kv[0])
+		resolved := strings.HasSuffix(event, HookResolvedSuffix)
+		if resolved {
+			event = strings.TrimSuffix(event, HookResolvedSuffix)
+		}
+		if event == "" {
+			return nil, fmt.Errorf("invalid hook '%s', expected 'event%s=command'", strings.TrimSpace(part), HookResolvedSuffix)
+		}
+		hooks = append(hooks, Hook{Event: event, Resolved: resolved, Command: strings.TrimSpace(kv[1])})
+	}
+	return hooks, nil
+}
+
+// Hooks runs local commands (with /bin/sh -c) on notifications, notification is in
+// environment variables GUARD_* and in JSON on stdin
+type Hooks struct {
+	hooks   []Hook
+	timeout time.Duration
+	sem     chan struct{} // limits concurrently running commands
+	wg      sync.WaitGroup
+	logger  tmlog.Logger
+}
+
+func NewHooks(hooks []Hook, timeout time.Duration, concurrency int, logger tmlog.Logger) *Hooks {
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultHookConcurrency
+	}
+	return &Hooks{
+		hooks:   hooks,
+		timeout: timeout,
+		sem:     make(chan struct{}, concurrency),
+		logger:  logger,
+	}
+}
+
+func (h *Hooks) Name() string {
+	return "hooks"
+}
+
+// Send starts matching hooks, it waits only for free slot when concurrency limit is reached.
+// Resolve notices start only hooks with ':resolved' event.
+func (h *Hooks) Send(n guard.Notification) error {
+	var input []byte
+	for _, hook := range h.hooks {
+		if hook.Resolved != n.Resolved {
+			continue
+		}
+		if hook.Event != "*" && hook.Event != n.Type && hook.Event != n.Key {
+			continue
+		}
+		if input == nil {
+			var err error
+			input, err = json.Marshal(n)
+			if err != nil {
+				return err
+			}
+		}
+		h.sem <- struct{}{}
+		h.wg.Add(1)
+		go func(hook Hook) {
+			defer func() {
+				<-h.sem
+				h.wg.Done()
+			}()
+			h.run(hook, n, input)
+		}(hook)
+	}
+	return nil
+}
+
+// Run waits for running hooks when dispatcher is stopped
+func (h *Hooks) Run(ctx context.Context) {
+	<-ctx.Done()
+	h.wg.Wait()
+}
+
+func (h *Hooks) run(hook Hook, n guard.Notification, input []byte) {
+	cmd := exec.Command("/bin/sh", "-c", hook.Command)
+	cmd.Env = append(os.Environ(), hookEnv(n)...)
+	cmd.Stdin = bytes.NewReader(input)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	setProcessGroup(cmd)
+	start := time.Now()
+	err := cmd.Start()
+	if err == nil {
+		// children of shell are killed too, otherwise they keep output open
+		timer := time.AfterFunc(h.timeout, func() {
+			killProcessGroup(cmd)
+		})
+		err = cmd.Wait()
+		if !timer.Stop() {
+			err = fmt.Errorf("timeout %s", h.timeout)
+		}
+	}
+	out := output.String()
+	if len(out) > maxHookOutput {
+		out = out[:maxHookOutput] + "..."
+	}
+	if err != nil {
+		h.logger.Error("hook failed", "command", hook.Command, "type", n.Type, "duration", time.Since(start), "err", err.Error(), "output", out)
+		return
+	}
+	h.logger.Info("hook finished", "command", hook.Command, "type", n.Type, "duration", time.Since(start), "output", out)
+}
+
+// hookEnv returns notification fields as GUARD_* variables, scalar details as GUARD_DETAIL_<KEY>
+func hookEnv(n guard.Notification) []string {
+	env := []string{
+		"GUARD_EVENT_TYPE=" + n.Type,
+		"GUARD_EVENT_KEY=" + n.Key,
+		"GUARD_SEVERITY=" + n.Severity,
+		fmt.Sprintf("GUARD_RESOLVED=%t", n.Resolved),
+		"GUARD_VALIDATOR=" + n.Validator,
+		fmt.Sprintf("GUARD_HEIGHT=%d", n.Height),
+		"GUARD_STATE=" + n.State,
+		"GUARD_MESSAGE=" + n.Message,
+		"GUARD_TIME=" + n.Time.UTC().Format(time.RFC3339),
+	}
+	keys := make([]string, 0, len(n.Details))
+	for k := range n.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := n.Details[k].(type) {
+		case string, bool, int, int64, uint64, float64:
+			env = append(env, fmt.Sprintf("GUARD_DETAIL_%s=%v", strings.ToUpper(k), v))
+		}
+	}
+	return env
+}
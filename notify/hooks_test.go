@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+func TestHooks(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	resolvedOut := filepath.Join(dir, "resolved")
+	hooks, err := ParseHooks("missed_blocks:50=echo $GUARD_EVENT_KEY $GUARD_DETAIL_MISSED > " + out + " && cat >> " + out +
+		"; set_offline=sleep 5; state_changed=exit 1; missed_blocks:50:resolved=echo $GUARD_RESOLVED >> " + resolvedOut)
+	require.NoError(t, err)
+	require.Len(t, hooks, 4)
+	require.Equal(t, Hook{Event: "missed_blocks:50", Resolved: true, Command: "echo $GUARD_RESOLVED >> " + resolvedOut}, hooks[3])
+
+	h := NewHooks(hooks, 100*time.Millisecond, 1, tmlog.NewTMLogger(dummyWriter{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+	require.NoError(t, h.Send(guard.Notification{
+		Type:    guard.NotifyMissedBlocks,
+		Key:     "missed_blocks:50",
+		Details: map[string]interface{}{"missed": 4},
+	}))
+	// timeout and failure are only logged
+	require.NoError(t, h.Send(guard.Notification{Type: guard.NotifySetOffline}))
+	require.NoError(t, h.Send(guard.Notification{Type: guard.NotifyStateChanged}))
+	// resolve notice starts only ':resolved' hook
+	require.NoError(t, h.Send(guard.Notification{Type: guard.NotifyMissedBlocks, Key: "missed_blocks:50", Resolved: true}))
+	cancel()
+	<-done
+
+	bz, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Contains(t, string(bz), "missed_blocks:50 4\n{\"type\":\"missed_blocks\",\"key\":\"missed_blocks:50\"")
+	require.NotContains(t, string(bz), "resolved")
+	bz, err = os.ReadFile(resolvedOut)
+	require.NoError(t, err)
+	require.Equal(t, "true\n", string(bz))
+
+	_, err = ParseHooks("missed_blocks")
+	require.Error(t, err)
+	_, err = ParseHooks(":resolved=true")
+	require.Error(t, err)
+}
@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+const QueueCapacity = 100 // notifications waiting for delivery per sender
+
+// Sender delivers notification to one destination (webhook, messenger etc.)
+type Sender interface {
+	Name() string
+	Send(n guard.Notification) error
+}
+
+// Runner is sender with own background work, for example bot answering commands
+type Runner interface {
+	Run(ctx context.Context)
+}
+
+// Dispatcher implements guard.Notifier: notifications are queued and delivered
+// in background, every sender has own queue so slow sender doesn't delay others
+type Dispatcher struct {
+	queues  []queue
+	logger  tmlog.Logger
+	wg      sync.WaitGroup // runners
+	sending sync.WaitGroup // queues
+	cancel  context.CancelFunc
+}
+
+type queue struct {
+	sender Sender
+	ch     chan guard.Notification
+}
+
+func NewDispatcher(logger tmlog.Logger, senders ...Sender) *Dispatcher {
+	d := &Dispatcher{logger: logger}
+	for _, s := range senders {
+		d.queues = append(d.queues, queue{sender: s, ch: make(chan guard.Notification, QueueCapacity)})
+	}
+	return d
+}
+
+// statusPageUrl returns link to human readable status page: STATUS_PAGE_URL or dashboard of http listener
+func statusPageUrl(config guard.Config) string {
+	if config.StatusPageUrl == "" && config.HttpListener > "" {
+		return "http://" + config.HttpListener + "/dashboard"
+	}
+	return config.StatusPageUrl
+}
+
+// NewFromConfig creates alert manager with senders enabled in configuration,
+// senders listed in ESCALATION_SENDERS get only escalated alerts
+func NewFromConfig(config guard.Config, source StatusSource, logger tmlog.Logger) (*AlertManager, error) {
+	var senders []Sender
+	if config.WebhookUrl > "" {
+		headers, err := ParseHeaders(config.WebhookHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_HEADERS: %s", err.Error())
+		}
+		senders = append(senders, NewWebhook(config.WebhookUrl, headers, config.WebhookRetries,
+			time.Duration(config.WebhookTimeout)*time.Second))
+	}
+	if config.TelegramToken > "" {
+		chats, err := ParseChatIDs(config.TelegramChatIDs)
+		if err != nil {
+			return nil, fmt.Errorf("TELEGRAM_CHAT_IDS: %s", err.Error())
+		}
+		if len(chats) == 0 {
+			return nil, errors.New("TELEGRAM_CHAT_IDS is empty")
+		}
+		senders = append(senders, NewTelegram(config.TelegramApiUrl, config.TelegramToken, chats, source, logger))
+	}
+	statusUrl := statusPageUrl(config)
+	if config.SlackWebhookUrl > "" {
+		senders = append(senders, NewSlack(config.SlackWebhookUrl, statusUrl, source))
+	}
+	if config.AlertmanagerUrl > "" {
+		senders = append(senders, NewAlertmanager(config.AlertmanagerUrl, statusUrl))
+	}
+	if config.SmtpHost > "" {
+		var to []string
+		for _, addr := range strings.Split(config.SmtpTo, ",") {
+			if strings.TrimSpace(addr) > "" {
+				to = append(to, strings.TrimSpace(addr))
+			}
+		}
+		email, err := NewEmail(config.SmtpHost, config.SmtpPort, config.SmtpSecurity, config.SmtpUsername,
+			config.SmtpPassword, config.SmtpFrom, to, source)
+		if err != nil {
+			return nil, fmt.Errorf("SMTP: %s", err.Error())
+		}
+		senders = append(senders, email)
+	}
+	if config.Hooks > "" {
+		hooks, err := ParseHooks(config.Hooks)
+		if err != nil {
+			return nil, fmt.Errorf("HOOKS: %s", err.Error())
+		}
+		senders = append(senders, NewHooks(hooks, time.Duration(config.HookTimeout)*time.Second, config.HookConcurrency, logger))
+	}
+	escalationNames := make(map[string]bool)
+	for _, name := range strings.Split(config.EscalationSenders, ",") {
+		if strings.TrimSpace(name) > "" {
+			escalationNames[strings.TrimSpace(name)] = true
+		}
+	}
+	var primary, escalation []Sender
+	for _, s := range senders {
+		if escalationNames[s.Name()] {
+			escalation = append(escalation, s)
+			delete(escalationNames, s.Name())
+		} else {
+			primary = append(primary, s)
+		}
+	}
+	for name := range escalationNames {
+		return nil, fmt.Errorf("ESCALATION_SENDERS: sender '%s' is not configured", name)
+	}
+	return NewAlertManager(NewDispatcher(logger, primary...), NewDispatcher(logger, escalation...),
+		time.Duration(config.AlertRenotifyInterval)*time.Minute, time.Duration(config.AlertEscalationAfter)*time.Minute, logger), nil
+}
+
+// Senders returns count of configured senders
+func (d *Dispatcher) Senders() int {
+	return len(d.queues)
+}
+
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	for _, q := range d.queues {
+		if r, ok := q.sender.(Runner); ok {
+			d.wg.Add(1)
+			go func() {
+				defer d.wg.Done()
+				r.Run(ctx)
+			}()
+		}
+		d.sending.Add(1)
+		go func(q queue) {
+			defer d.sending.Done()
+			for n := range q.ch {
+				if err := q.sender.Send(n); err != nil {
+					d.logger.Error("can't send notification", "sender", q.sender.Name(), "type", n.Type, "err", err.Error())
+				}
+			}
+		}(q)
+	}
+}
+
+// Stop delivers queued notifications and stops, runners are stopped after delivery: hooks wait for started commands
+func (d *Dispatcher) Stop() {
+	for _, q := range d.queues {
+		close(q.ch)
+	}
+	d.sending.Wait()
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Notify never blocks: notification is dropped if queue of sender is full
+func (d *Dispatcher) Notify(n guard.Notification) {
+	d.notify(n, false)
+}
+
+// Repeat sends reminder of open alert, hooks don't get it: their commands must run once per alert
+func (d *Dispatcher) Repeat(n guard.Notification) {
+	d.notify(n, true)
+}
+
+func (d *Dispatcher) notify(n guard.Notification, repeat bool) {
+	for _, q := range d.queues {
+		if _, hooks := q.sender.(*Hooks); hooks && repeat {
+			continue
+		}
+		select {
+		case q.ch <- n:
+		default:
+			d.logger.Error("notification queue is full, notification is dropped", "sender", q.sender.Name(), "type", n.Type)
+		}
+	}
+}
+
+// reportable returns true for notifications with severity at least min and for digests
+func reportable(n guard.Notification, min guard.Severity) bool {
+	return n.Type == guard.NotifyDigest || severityRank[n.Severity] >= severityRank[min]
+}
+
+// permanentError is not retried (for example invalid request)
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// retry calls f up to 1+retries times with growing pause
+func retry(retries int, pause time.Duration, f func() error) error {
+	var err error
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			time.Sleep(pause * time.Duration(i))
+		}
+		err = f()
+		var perm permanentError
+		if err == nil || errors.As(err, &perm) {
+			return err
+		}
+	}
+	return err
+}
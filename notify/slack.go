@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+var severityColors = map[guard.Severity]string{
+	guard.SeverityInfo:     "#2eb886",
+	guard.SeverityWarning:  "#daa038",
+	guard.SeverityCritical: "#a30200",
+}
+
+// Slack posts alerts to Slack or Mattermost incoming webhook (both accept the same attachments)
+type Slack struct {
+	url        string
+	statusUrl  string
+	source     StatusSource
+	retries    int
+	retryPause time.Duration
+	client     *http.Client
+}
+
+func NewSlack(url, statusUrl string, source StatusSource) *Slack {
+	return &Slack{
+		url:        url,
+		statusUrl:  statusUrl,
+		source:     source,
+		retries:    DefaultWebhookRetries,
+		retryPause: time.Second,
+		client:     &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+}
+
+func (sl *Slack) Name() string {
+	return "slack"
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Fallback  string       `json:"fallback"`
+	Color     string       `json:"color"`
+	Title     string       `json:"title"`
+	TitleLink string       `json:"title_link,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Fields    []slackField `json:"fields"`
+	Footer    string       `json:"footer"`
+	Ts        int64        `json:"ts"`
+}
+
+type slackMessage struct {
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// Send delivers alerts and digests, informational notifications are skipped
+func (sl *Slack) Send(n guard.Notification) error {
+	if !reportable(n, guard.SeverityWarning) {
+		return nil
+	}
+	bz, err := json.Marshal(sl.message(n))
+	if err != nil {
+		return err
+	}
+	return retry(sl.retries, sl.retryPause, func() error {
+		return sl.post(bz)
+	})
+}
+
+func (sl *Slack) message(n guard.Notification) slackMessage {
+	color := severityColors[n.Severity]
+	if n.Resolved {
+		color = severityColors[guard.SeverityInfo]
+	}
+	ws := sl.source.SignWindow()
+	fields := []slackField{
+		{Title: "State", Value: n.State, Short: true},
+		{Title: "Height", Value: fmt.Sprint(n.Height), Short: true},
+		{Title: "Missed blocks", Value: fmt.Sprintf("%d of %d (limit %d)", ws.Missed, ws.Size, ws.Limit), Short: true},
+		{Title: "Window", Value: ws.Marks(), Short: false},
+		{Title: "Validator", Value: n.Validator, Short: false},
+	}
+	return slackMessage{Attachments: []slackAttachment{{
+		Fallback:  fmt.Sprintf("[%s] %s", n.Severity, n.Message),
+		Color:     color,
+		Title:     n.Message,
+		TitleLink: sl.statusUrl,
+		Text:      formatMap(n.Details),
+		Fields:    fields,
+		Footer:    "dsc-guard " + n.Type,
+		Ts:        n.Time.Unix(),
+	}}}
+}
+
+func (sl *Slack) post(bz []byte) error {
+	resp, err := sl.client.Post(sl.url, "application/json", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("http code %d: %s", resp.StatusCode, body)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
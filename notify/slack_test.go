@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+func TestSlack(t *testing.T) {
+	received := make(chan slackMessage, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		received <- msg
+	}))
+	defer srv.Close()
+
+	sl := NewSlack(srv.URL, "https://guard.example.com/", stubSource{})
+	require.NoError(t, sl.Send(guard.Notification{
+		Type:     guard.NotifyWatcherDown,
+		Severity: guard.SeverityWarning,
+		State:    "watching",
+		Height:   100,
+		Message:  "watcher node1 is disconnected from node",
+		Time:     time.Unix(1700000000, 0),
+	}))
+	msg := <-received
+	require.Len(t, msg.Attachments, 1)
+	att := msg.Attachments[0]
+	require.Equal(t, "#daa038", att.Color)
+	require.Equal(t, "https://guard.example.com/", att.TitleLink)
+	require.Equal(t, "watching", att.Fields[0].Value)
+	require.Equal(t, "1 of 3 (limit 2)", att.Fields[2].Value)
+	require.Equal(t, "?-+", att.Fields[3].Value)
+	require.Equal(t, int64(1700000000), att.Ts)
+}
+
+func TestStatusPageUrl(t *testing.T) {
+	require.Equal(t, "http://127.0.0.1:8080/dashboard", statusPageUrl(guard.Config{HttpListener: "127.0.0.1:8080"}))
+	require.Equal(t, "https://guard.example.com/", statusPageUrl(guard.Config{HttpListener: "127.0.0.1:8080", StatusPageUrl: "https://guard.example.com/"}))
+	require.Equal(t, "", statusPageUrl(guard.Config{}))
+}
@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+const (
+	DefaultTelegramApi  = "https://api.telegram.org"
+	telegramPollTimeout = 30 // seconds of long polling in getUpdates
+)
+
+// Telegram sends alerts to chats and answers /status and /window commands from the same chats
+type Telegram struct {
+	api        string
+	token      string
+	chats      []int64
+	source     StatusSource
+	retries    int
+	retryPause time.Duration
+	client     *http.Client
+	logger     tmlog.Logger
+}
+
+func NewTelegram(api, token string, chats []int64, source StatusSource, logger tmlog.Logger) *Telegram {
+	if api == "" {
+		api = DefaultTelegramApi
+	}
+	return &Telegram{
+		api:        strings.TrimRight(api, "/"),
+		token:      token,
+		chats:      chats,
+		source:     source,
+		retries:    DefaultWebhookRetries,
+		retryPause: time.Second,
+		client:     &http.Client{Timeout: (telegramPollTimeout + 10) * time.Second},
+		logger:     logger,
+	}
+}
+
+// ParseChatIDs parses list of chat ids separated by ','
+func ParseChatIDs(s string) ([]int64, error) {
+	var chats []int64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat id '%s'", part)
+		}
+		chats = append(chats, id)
+	}
+	return chats, nil
+}
+
+func (tg *Telegram) Name() string {
+	return "telegram"
+}
+
+// Send delivers alerts and digests, informational notifications are skipped
+func (tg *Telegram) Send(n guard.Notification) error {
+	if !reportable(n, guard.SeverityWarning) {
+		return nil
+	}
+	text := formatText(n)
+	var lastErr error
+	for _, chat := range tg.chats {
+		err := retry(tg.retries, tg.retryPause, func() error {
+			return tg.sendMessage(chat, text)
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("chat %d: %s", chat, err.Error())
+		}
+	}
+	return lastErr
+}
+
+func (tg *Telegram) method(name string) string {
+	return fmt.Sprintf("%s/bot%s/%s", tg.api, tg.token, name)
+}
+
+func (tg *Telegram) sendMessage(chat int64, text string) error {
+	bz, err := json.Marshal(map[string]interface{}{"chat_id": chat, "text": text})
+	if err != nil {
+		return permanentError{err}
+	}
+	resp, err := tg.client.Post(tg.method("sendMessage"), "application/json", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("http code %d: %s", resp.StatusCode, body)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
+
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// Run answers commands until context is cancelled
+func (tg *Telegram) Run(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := tg.getUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				tg.logger.Error("telegram getUpdates", "err", err.Error())
+				select {
+				case <-ctx.Done():
+				case <-time.After(tg.retryPause * 5):
+				}
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message != nil {
+				tg.handleCommand(u.Message.Chat.ID, u.Message.Text)
+			}
+		}
+	}
+}
+
+func (tg *Telegram) getUpdates(ctx context.Context, offset int64) ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(offset, 10))
+	params.Set("timeout", strconv.Itoa(telegramPollTimeout))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tg.method("getUpdates")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := tg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Ok          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if !result.Ok {
+		return nil, fmt.Errorf("%s", result.Description)
+	}
+	return result.Result, nil
+}
+
+// handleCommand answers only to configured chats
+func (tg *Telegram) handleCommand(chat int64, text string) {
+	allowed := false
+	for _, c := range tg.chats {
+		allowed = allowed || c == chat
+	}
+	if !allowed {
+		return
+	}
+	// '/status@guard_bot' in group chats
+	command := strings.SplitN(strings.TrimSpace(strings.SplitN(text, " ", 2)[0]), "@", 2)[0]
+	var reply string
+	switch command {
+	case "/status":
+		reply = formatStatus(tg.source.Status())
+	case "/window":
+		reply = formatWindow(tg.source.SignWindow())
+	default:
+		return
+	}
+	if err := tg.sendMessage(chat, reply); err != nil {
+		tg.logger.Error("telegram reply", "command", command, "err", err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+type stubSource struct{}
+
+func (s stubSource) Status() guard.GuardStatus {
+	return guard.GuardStatus{Version: guard.StatusVersion, CurrentHeight: 100, TransactionStatus: "valid", Window: s.SignWindow()}
+}
+
+func (s stubSource) SignWindow() guard.WindowStatus {
+	return guard.WindowStatus{Height: 100, Size: 3, Missed: 1, Limit: 2, LastSigned: 100, Blocks: []guard.WindowBlock{
+		{Height: 98, Sign: guard.BlockUnknown}, {Height: 99, Sign: guard.BlockMissed}, {Height: 100, Sign: guard.BlockSigned},
+	}}
+}
+
+type sentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func TestTelegram(t *testing.T) {
+	var polls int32
+	sent := make(chan sentMessage, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botTOKEN/sendMessage":
+			var msg sentMessage
+			json.NewDecoder(r.Body).Decode(&msg)
+			sent <- msg
+			w.Write([]byte(`{"ok":true}`))
+		case "/botTOKEN/getUpdates":
+			if atomic.AddInt32(&polls, 1) == 1 {
+				w.Write([]byte(`{"ok":true,"result":[
+					{"update_id":1,"message":{"chat":{"id":-5},"text":"/status"}},
+					{"update_id":2,"message":{"chat":{"id":42},"text":"/window@guard_bot"}}]}`))
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+			w.Write([]byte(`{"ok":true,"result":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	chats, err := ParseChatIDs("42, 43")
+	require.NoError(t, err)
+	tg := NewTelegram(srv.URL, "TOKEN", chats, stubSource{}, tmlog.NewTMLogger(dummyWriter{}))
+
+	// alerts go to every chat, info is skipped
+	require.NoError(t, tg.Send(guard.Notification{Type: guard.NotifyStateChanged, Severity: guard.SeverityInfo}))
+	require.NoError(t, tg.Send(guard.Notification{Type: guard.NotifyTxInvalid, Severity: guard.SeverityCritical, Message: "set_offline transaction is invalid"}))
+	require.Equal(t, int64(42), (<-sent).ChatID)
+	msg := <-sent
+	require.Equal(t, int64(43), msg.ChatID)
+	require.Contains(t, msg.Text, "[CRITICAL] set_offline transaction is invalid")
+
+	// command from unknown chat is ignored
+	ctx, cancel := context.WithCancel(context.Background())
+	go tg.Run(ctx)
+	msg = <-sent
+	cancel()
+	require.Equal(t, int64(42), msg.ChatID)
+	require.Equal(t, "height 100, missed 1 of 3 blocks (limit 2), 0 in a row, last signed 100\n?-+\nmissed: 99", msg.Text)
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+const (
+	DefaultWebhookRetries = 3
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// Webhook POSTs notification as JSON to any URL
+type Webhook struct {
+	url        string
+	headers    http.Header
+	retries    int
+	retryPause time.Duration
+	client     *http.Client
+}
+
+// NewWebhook creates webhook sender, retries = 0 disables retries and negative value means default
+func NewWebhook(url string, headers http.Header, retries int, timeout time.Duration) *Webhook {
+	if retries < 0 {
+		retries = DefaultWebhookRetries
+	}
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &Webhook{
+		url:        url,
+		headers:    headers,
+		retries:    retries,
+		retryPause: time.Second,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (wh *Webhook) Name() string {
+	return "webhook"
+}
+
+func (wh *Webhook) Send(n guard.Notification) error {
+	bz, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return retry(wh.retries, wh.retryPause, func() error {
+		return wh.post(bz)
+	})
+}
+
+func (wh *Webhook) post(bz []byte) error {
+	req, err := http.NewRequest(http.MethodPost, wh.url, bytes.NewReader(bz))
+	if err != nil {
+		return permanentError{err}
+	}
+	for name, values := range wh.headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("http code %d: %s", resp.StatusCode, body)
+	// client errors will be the same on retry
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
+
+// ParseHeaders parses headers in form 'Name: value; Name2: value2'
+func ParseHeaders(s string) (http.Header, error) {
+	headers := http.Header{}
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid header '%s', expected 'Name: value'", strings.TrimSpace(part))
+		}
+		headers.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+	return headers, nil
+}
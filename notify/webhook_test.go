@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"bitbucket.org/decimalteam/dsc-guard/guard"
+)
+
+type dummyWriter struct{}
+
+func (d dummyWriter) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+func TestWebhook(t *testing.T) {
+	var calls int32
+	received := make(chan guard.Notification, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		require.Equal(t, "secret", r.Header.Get("X-Token"))
+		var n guard.Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received <- n
+	}))
+	defer srv.Close()
+
+	headers, err := ParseHeaders("X-Token: secret; X-Source: guard")
+	require.NoError(t, err)
+	wh := NewWebhook(srv.URL, headers, 3, 0)
+	wh.retryPause = 0
+	d := NewDispatcher(tmlog.NewTMLogger(dummyWriter{}), wh)
+	d.Start()
+	d.Notify(guard.Notification{Type: guard.NotifyTxInvalid, Validator: "AA", Height: 10, State: "watching_without_tx"})
+	d.Stop()
+
+	n := <-received
+	require.Equal(t, guard.NotifyTxInvalid, n.Type)
+	require.Equal(t, int64(10), n.Height)
+	require.Equal(t, int32(3), calls)
+
+	// client error is not retried
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer bad.Close()
+	calls = 0
+	wh = NewWebhook(bad.URL, nil, 3, 0)
+	wh.retryPause = 0
+	require.Error(t, wh.Send(guard.Notification{}))
+	require.Equal(t, int32(1), calls)
+
+	// retries are disabled
+	fails := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer fails.Close()
+	calls = 0
+	wh = NewWebhook(fails.URL, nil, 0, 0)
+	wh.retryPause = 0
+	require.Error(t, wh.Send(guard.Notification{}))
+	require.Equal(t, int32(1), calls)
+
+	_, err = ParseHeaders("broken")
+	require.Error(t, err)
+}
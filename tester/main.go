@@ -29,6 +29,19 @@ func (sg *stubGuard) ReportWatcher(id string, state guard.WatcherState) {
 func (sg *stubGuard) ReportTxValidity(id string, valid bool) {
 	sg.logger.Debug(fmt.Sprintf("ReportTxValidity(%s) valid=%v", id, valid))
 }
+
+func (sg *stubGuard) ReportWatcherError(id string, err error) {
+	sg.logger.Debug(fmt.Sprintf("ReportWatcherError(%s) %s", id, err.Error()))
+}
+
+func (sg *stubGuard) ReportWatcherOk(id string) {
+	sg.logger.Debug(fmt.Sprintf("ReportWatcherOk(%s)", id))
+}
+
+func (sg *stubGuard) ReportTxDiagnosis(id string, reason guard.TxInvalidReason, details string) {
+	sg.logger.Debug(fmt.Sprintf("ReportTxDiagnosis(%s) reason=%s details=%s", id, guard.TxReasonName(reason), details))
+}
+
 func (sg *stubGuard) ReportValidatorOnline(id string, height int64, online bool) {
 	sg.logger.Debug(fmt.Sprintf("ReportValidatorOnline(%s) height=%d online=%v", id, height, online))
 }
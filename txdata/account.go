@@ -0,0 +1,82 @@
+package txdata
+
+import (
+	"fmt"
+
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	ethermint "github.com/evmos/ethermint/types"
+
+	"bitbucket.org/decimalteam/dsc-guard/fastclient"
+)
+
+const (
+	queryAccountPath = "/cosmos.auth.v1beta1.Query/Account"
+
+	typeBaseAccount = "/cosmos.auth.v1beta1.BaseAccount"
+	typeEthAccount  = "/ethermint.types.v1.EthAccount"
+)
+
+// Account is on-chain state of account from auth module
+type Account struct {
+	Address       string
+	AccountNumber uint64
+	Sequence      uint64
+	PubKey        cryptoTypes.PubKey // nil if account never sent transactions
+}
+
+// QueryAccount requests account state through abci_query to auth module
+func QueryAccount(client *fastclient.FastClient, address string) (Account, error) {
+	req := authTypes.QueryAccountRequest{Address: address}
+	data, err := req.Marshal()
+	if err != nil {
+		return Account{}, err
+	}
+	res, err := client.ABCIQuery(queryAccountPath, data)
+	if err != nil {
+		return Account{}, err
+	}
+	if res.Code != 0 {
+		return Account{}, fmt.Errorf("query account %s: code=%d, codespace=%s, log=%s", address, res.Code, res.Codespace, res.Log)
+	}
+	var resp authTypes.QueryAccountResponse
+	if err := resp.Unmarshal(res.Value); err != nil {
+		return Account{}, fmt.Errorf("decode account %s: %s", address, err.Error())
+	}
+	if resp.Account == nil {
+		return Account{}, fmt.Errorf("account %s not found", address)
+	}
+
+	var base *authTypes.BaseAccount
+	switch resp.Account.TypeUrl {
+	case typeEthAccount:
+		var acc ethermint.EthAccount
+		if err := acc.Unmarshal(resp.Account.Value); err != nil {
+			return Account{}, fmt.Errorf("decode account %s: %s", address, err.Error())
+		}
+		base = acc.BaseAccount
+	case typeBaseAccount:
+		base = &authTypes.BaseAccount{}
+		if err := base.Unmarshal(resp.Account.Value); err != nil {
+			return Account{}, fmt.Errorf("decode account %s: %s", address, err.Error())
+		}
+	default:
+		return Account{}, fmt.Errorf("unsupported account type %s", resp.Account.TypeUrl)
+	}
+	if base == nil {
+		return Account{}, fmt.Errorf("account %s has no base account", address)
+	}
+
+	acc := Account{
+		Address:       base.Address,
+		AccountNumber: base.AccountNumber,
+		Sequence:      base.Sequence,
+	}
+	if base.PubKey != nil {
+		acc.PubKey, err = decodePubKey(base.PubKey.TypeUrl, base.PubKey.Value)
+		if err != nil {
+			return Account{}, err
+		}
+	}
+	return acc, nil
+}
@@ -0,0 +1,90 @@
+package txdata
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authSigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authTx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+
+	dscTx "bitbucket.org/decimalteam/dsc-go-sdk/tx"
+	dscWallet "bitbucket.org/decimalteam/dsc-go-sdk/wallet"
+)
+
+const ValidatorPrefix = dscWallet.Bech32Prefix + "valoper"
+
+// Signer is private key holder: key from mnemonic, keyring etc.
+type Signer interface {
+	PubKey() cryptoTypes.PubKey
+	Sign(msg []byte) ([]byte, error)
+}
+
+// SignerData is account data required to sign transaction
+type SignerData struct {
+	ChainID       string
+	AccountNumber uint64
+	Sequence      uint64
+}
+
+// BuildSetOffline returns signed set_offline transaction for validator of signer
+func BuildSetOffline(signer Signer, data SignerData, fee sdk.Coin) ([]byte, error) {
+	operator, err := bech32.ConvertAndEncode(ValidatorPrefix, signer.PubKey().Address())
+	if err != nil {
+		return nil, err
+	}
+	return build(signer, data, &dscTx.MsgSetOffline{Validator: operator}, fee)
+}
+
+// BuildSetOnline returns signed set_online transaction for validator of signer
+func BuildSetOnline(signer Signer, data SignerData, fee sdk.Coin) ([]byte, error) {
+	operator, err := bech32.ConvertAndEncode(ValidatorPrefix, signer.PubKey().Address())
+	if err != nil {
+		return nil, err
+	}
+	return build(signer, data, &dscTx.MsgSetOnline{Validator: operator}, fee)
+}
+
+// SignerAddress returns bech32 account address of signer
+func SignerAddress(signer Signer) (string, error) {
+	return bech32.ConvertAndEncode(dscWallet.Bech32Prefix, signer.PubKey().Address())
+}
+
+func build(signer Signer, data SignerData, msg sdk.Msg, fee sdk.Coin) ([]byte, error) {
+	const signMode = signing.SignMode_SIGN_MODE_DIRECT
+	txConfig := authTx.NewTxConfig(codec.NewProtoCodec(codecTypes.NewInterfaceRegistry()), authTx.DefaultSignModes)
+	builder := txConfig.NewTxBuilder()
+	if err := builder.SetMsgs(msg); err != nil {
+		return nil, err
+	}
+	builder.SetFeeAmount(sdk.NewCoins(fee))
+
+	// signature with empty data is required to get bytes to sign
+	sig := signing.SignatureV2{
+		PubKey:   signer.PubKey(),
+		Data:     &signing.SingleSignatureData{SignMode: signMode},
+		Sequence: data.Sequence,
+	}
+	if err := builder.SetSignatures(sig); err != nil {
+		return nil, err
+	}
+	bytesToSign, err := txConfig.SignModeHandler().GetSignBytes(signMode, authSigning.SignerData{
+		ChainID:       data.ChainID,
+		AccountNumber: data.AccountNumber,
+		Sequence:      data.Sequence,
+	}, builder.GetTx())
+	if err != nil {
+		return nil, err
+	}
+	sigBytes, err := signer.Sign(bytesToSign)
+	if err != nil {
+		return nil, err
+	}
+	sig.Data = &signing.SingleSignatureData{SignMode: signMode, Signature: sigBytes}
+	if err := builder.SetSignatures(sig); err != nil {
+		return nil, err
+	}
+	return txConfig.TxEncoder()(builder.GetTx())
+}
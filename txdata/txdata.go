@@ -0,0 +1,157 @@
+package txdata
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/evmos/ethermint/crypto/ethsecp256k1"
+
+	dscTx "bitbucket.org/decimalteam/dsc-go-sdk/tx"
+	dscWallet "bitbucket.org/decimalteam/dsc-go-sdk/wallet"
+)
+
+const (
+	TypeMsgSetOffline = "/decimal.validator.v1.MsgSetOffline"
+	TypeMsgSetOnline  = "/decimal.validator.v1.MsgSetOnline"
+
+	typeEthPubKey    = "/ethermint.crypto.v1.ethsecp256k1.PubKey"
+	typeCosmosPubKey = "/cosmos.crypto.secp256k1.PubKey"
+)
+
+// TxInfo is decoded signed transaction (set_offline, set_online)
+type TxInfo struct {
+	Messages  []string // type urls of messages
+	Validator string   // validator operator address from first message
+	Memo      string
+	Fee       sdk.Coins
+	GasLimit  uint64
+
+	Signer    string // bech32 address derived from signer public key
+	PubKey    cryptoTypes.PubKey
+	Sequence  uint64
+	Signature []byte
+
+	bodyBytes     []byte
+	authInfoBytes []byte
+}
+
+// Decode parses protobuf-encoded transaction (as it is broadcasted to node)
+func Decode(bz []byte) (TxInfo, error) {
+	var raw txTypes.TxRaw
+	if err := raw.Unmarshal(bz); err != nil {
+		return TxInfo{}, fmt.Errorf("decode tx: %s", err.Error())
+	}
+	var body txTypes.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return TxInfo{}, fmt.Errorf("decode tx body: %s", err.Error())
+	}
+	var authInfo txTypes.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return TxInfo{}, fmt.Errorf("decode tx auth info: %s", err.Error())
+	}
+	if len(authInfo.SignerInfos) != 1 || len(raw.Signatures) != 1 {
+		return TxInfo{}, fmt.Errorf("expected exactly one signer, got %d signer infos and %d signatures",
+			len(authInfo.SignerInfos), len(raw.Signatures))
+	}
+
+	info := TxInfo{
+		Memo:          body.Memo,
+		Sequence:      authInfo.SignerInfos[0].Sequence,
+		Signature:     raw.Signatures[0],
+		bodyBytes:     raw.BodyBytes,
+		authInfoBytes: raw.AuthInfoBytes,
+	}
+	if authInfo.Fee != nil {
+		info.Fee = authInfo.Fee.Amount
+		info.GasLimit = authInfo.Fee.GasLimit
+	}
+	for i, msg := range body.Messages {
+		info.Messages = append(info.Messages, msg.TypeUrl)
+		if i > 0 {
+			continue
+		}
+		switch msg.TypeUrl {
+		case TypeMsgSetOffline:
+			var m dscTx.MsgSetOffline
+			if err := m.Unmarshal(msg.Value); err != nil {
+				return TxInfo{}, fmt.Errorf("decode %s: %s", msg.TypeUrl, err.Error())
+			}
+			info.Validator = m.Validator
+		case TypeMsgSetOnline:
+			var m dscTx.MsgSetOnline
+			if err := m.Unmarshal(msg.Value); err != nil {
+				return TxInfo{}, fmt.Errorf("decode %s: %s", msg.TypeUrl, err.Error())
+			}
+			info.Validator = m.Validator
+		}
+	}
+
+	pk, err := decodePubKey(authInfo.SignerInfos[0].PublicKey.GetTypeUrl(), authInfo.SignerInfos[0].PublicKey.GetValue())
+	if err != nil {
+		return TxInfo{}, err
+	}
+	if pk != nil {
+		info.PubKey = pk
+		info.Signer, err = bech32.ConvertAndEncode(dscWallet.Bech32Prefix, pk.Address())
+		if err != nil {
+			return TxInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+// SignBytes returns bytes to sign in SIGN_MODE_DIRECT for given chain id and account number
+func (info TxInfo) SignBytes(chainID string, accountNumber uint64) ([]byte, error) {
+	doc := txTypes.SignDoc{
+		BodyBytes:     info.bodyBytes,
+		AuthInfoBytes: info.authInfoBytes,
+		ChainId:       chainID,
+		AccountNumber: accountNumber,
+	}
+	return doc.Marshal()
+}
+
+// VerifySignature returns true if transaction is signed by pubKey for given chain id and account number
+func (info TxInfo) VerifySignature(pubKey cryptoTypes.PubKey, chainID string, accountNumber uint64) bool {
+	if pubKey == nil {
+		return false
+	}
+	bz, err := info.SignBytes(chainID, accountNumber)
+	if err != nil {
+		return false
+	}
+	return pubKey.VerifySignature(bz, info.Signature)
+}
+
+// OperatorAccount converts validator operator address (d0valoper...) to account address (d0...)
+func OperatorAccount(valoper string) (string, error) {
+	_, bz, err := bech32.DecodeAndConvert(valoper)
+	if err != nil {
+		return "", err
+	}
+	return bech32.ConvertAndEncode(dscWallet.Bech32Prefix, bz)
+}
+
+func decodePubKey(typeUrl string, value []byte) (cryptoTypes.PubKey, error) {
+	switch typeUrl {
+	case "":
+		return nil, nil
+	case typeEthPubKey:
+		var pk ethsecp256k1.PubKey
+		if err := pk.Unmarshal(value); err != nil {
+			return nil, fmt.Errorf("decode public key: %s", err.Error())
+		}
+		return &pk, nil
+	case typeCosmosPubKey:
+		var pk secp256k1.PubKey
+		if err := pk.Unmarshal(value); err != nil {
+			return nil, fmt.Errorf("decode public key: %s", err.Error())
+		}
+		return &pk, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %s", typeUrl)
+}